@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"net/http"
+)
+
+// Паливо з довідника: тверде паливо за робочою масою, мазут за горючою масою
+// або газ за об'ємним складом
+type Fuel struct {
+	ID    string            `json:"id"`
+	Name  string            `json:"name"`
+	Solid *SolidComposition `json:"solid,omitempty"`
+	Oil   *OilComposition   `json:"oil,omitempty"`
+	Gas   *GasComposition   `json:"gas,omitempty"`
+}
+
+// Початковий вміст довідника, який отримує кожна команда
+var seedFuels = []Fuel{
+	{
+		ID:    "donetsk-coal-d",
+		Name:  "Донецьке вугілля марки Д",
+		Solid: &SolidComposition{HP: 3.6, CP: 49.3, SP: 3.0, NP: 1.0, OP: 8.3, WP: 13.0, AP: 21.8},
+	},
+	{
+		ID:    "donetsk-coal-g",
+		Name:  "Донецьке вугілля марки Г",
+		Solid: &SolidComposition{HP: 3.8, CP: 55.2, SP: 3.2, NP: 1.0, OP: 5.8, WP: 8.0, AP: 23.0},
+	},
+	{
+		ID:    "donetsk-anthracite-ash",
+		Name:  "Донецький антрацит АШ",
+		Solid: &SolidComposition{HP: 1.2, CP: 63.8, SP: 1.7, NP: 0.6, OP: 1.3, WP: 8.5, AP: 22.9},
+	},
+	{
+		ID:   "mazut-high-sulfur",
+		Name: "Мазут високосірчистий",
+		Oil:  &OilComposition{H: 11.2, C: 85.5, S: 2.5, O: 0.8, W: 2.0, A: 0.15, V: 333.3, QFO: 40.4},
+	},
+	{
+		ID:   "mazut-low-sulfur",
+		Name: "Мазут малосірчистий",
+		Oil:  &OilComposition{H: 11.7, C: 87.3, S: 0.5, O: 0.5, W: 1.0, A: 0.05, V: 50, QFO: 41.0},
+	},
+	{
+		ID:   "natural-gas",
+		Name: "Природний газ",
+		Gas:  &GasComposition{CH4: 98.9, C2H6: 0.12, C3H8: 0.01, N2: 0.91, CO2: 0.06},
+	},
+}
+
+var fuelCatalogue *Catalogue[Fuel]
+
+func requestTeam(c *gin.Context) string {
+	return c.DefaultQuery("team", "default")
+}
+
+func validateFuel(fuel Fuel) error {
+	if fuel.ID == "" || fuel.Name == "" {
+		return errors.New("Паливо повинно мати id та name")
+	}
+	compositions := 0
+	for _, set := range []bool{fuel.Solid != nil, fuel.Oil != nil, fuel.Gas != nil} {
+		if set {
+			compositions++
+		}
+	}
+	if compositions != 1 {
+		return errors.New("Потрібно задати один зі складів: solid, oil або gas")
+	}
+
+	// Склад перевіряється так само, як у розрахунках, але без нормалізації
+	var compositionErr *CompositionError
+	switch {
+	case fuel.Solid != nil:
+		compositionErr = (&CalculationRequest1{SolidComposition: *fuel.Solid}).validate()
+	case fuel.Oil != nil:
+		compositionErr = (&CalculationRequest2{OilComposition: *fuel.Oil}).validate()
+	case fuel.Gas != nil:
+		_, compositionErr = validateComposition(fuel.Gas.components(), ValidationOptions{})
+	}
+	if compositionErr != nil {
+		return compositionErr
+	}
+	return nil
+}
+
+// Відповідь на помилку перевірки палива: для складу — з діагностикою по компонентах
+func fuelError(err error) any {
+	var compositionErr *CompositionError
+	if errors.As(err, &compositionErr) {
+		return compositionErr
+	}
+	return gin.H{"error": err.Error()}
+}
+
+// Заповнення запиту складом палива з довідника за fuelId;
+// поля, передані в тілі запиту, перевизначають значення з довідника
+func bindWithFuel(c *gin.Context, req any, fill func(Fuel) bool) error {
+	var ref struct {
+		FuelID string `json:"fuelId"`
+	}
+	if err := c.ShouldBindBodyWith(&ref, binding.JSON); err != nil {
+		return err
+	}
+
+	if ref.FuelID != "" {
+		fuel, ok := fuelCatalogue.Get(requestTeam(c), ref.FuelID)
+		if !ok {
+			return errors.New("Паливо не знайдено в довіднику: " + ref.FuelID)
+		}
+		if !fill(fuel) {
+			return errors.New("Паливо " + ref.FuelID + " не підходить для цього розрахунку")
+		}
+	}
+
+	return c.ShouldBindBodyWith(req, binding.JSON)
+}
+
+func listFuels(c *gin.Context) {
+	c.JSON(http.StatusOK, fuelCatalogue.List(requestTeam(c)))
+}
+
+func getFuel(c *gin.Context) {
+	fuel, ok := fuelCatalogue.Get(requestTeam(c), c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Паливо не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, fuel)
+}
+
+func createFuel(c *gin.Context) {
+	var fuel Fuel
+	if err := c.ShouldBindJSON(&fuel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateFuel(fuel); err != nil {
+		c.JSON(http.StatusBadRequest, fuelError(err))
+		return
+	}
+
+	created, err := fuelCatalogue.Create(requestTeam(c), fuel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Паливо з таким id вже існує"})
+		return
+	}
+	c.JSON(http.StatusCreated, fuel)
+}
+
+func updateFuel(c *gin.Context) {
+	var fuel Fuel
+	if err := c.ShouldBindJSON(&fuel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	fuel.ID = c.Param("id")
+	if err := validateFuel(fuel); err != nil {
+		c.JSON(http.StatusBadRequest, fuelError(err))
+		return
+	}
+
+	updated, err := fuelCatalogue.Update(requestTeam(c), fuel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Паливо не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, fuel)
+}
+
+func deleteFuel(c *gin.Context) {
+	deleted, err := fuelCatalogue.Delete(requestTeam(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Паливо не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
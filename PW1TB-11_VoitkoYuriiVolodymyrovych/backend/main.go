@@ -1,14 +1,18 @@
 package main
 
 import (
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"log"
+	"math"
 	"net/http"
+	"os"
 	"time"
 )
 
-type CalculationRequest1 struct {
+// Склад робочої маси твердого палива, %
+type SolidComposition struct {
 	HP float64 `json:"hp"`
 	CP float64 `json:"cp"`
 	SP float64 `json:"sp"`
@@ -18,7 +22,9 @@ type CalculationRequest1 struct {
 	AP float64 `json:"ap"`
 }
 
-type CalculationRequest2 struct {
+// Склад горючої маси мазуту, %, вологість і зольність робочої маси, %,
+// вміст ванадію та натрію, мг/кг, і теплота згоряння горючої маси, МДж/кг
+type OilComposition struct {
 	H   float64 `json:"h"`
 	C   float64 `json:"c"`
 	S   float64 `json:"s"`
@@ -27,18 +33,363 @@ type CalculationRequest2 struct {
 	A   float64 `json:"a"`
 	V   float64 `json:"v"`
 	QFO float64 `json:"qFO"`
+	Na  float64 `json:"na"`
+}
+
+// Об'ємний склад газоподібного палива, %
+type GasComposition struct {
+	CH4  float64 `json:"ch4"`
+	C2H6 float64 `json:"c2h6"`
+	C3H8 float64 `json:"c3h8"`
+	H2   float64 `json:"h2"`
+	CO   float64 `json:"co"`
+	CO2  float64 `json:"co2"`
+	N2   float64 `json:"n2"`
+	H2S  float64 `json:"h2s"`
+}
+
+type CalculationRequest1 struct {
+	SolidComposition
+
+	Correlations []string `json:"correlations,omitempty"`
+
+	ValidationOptions
+}
+
+type CalculationRequest2 struct {
+	OilComposition
+
+	Alpha  float64                  `json:"alpha,omitempty"`
+	Limits map[string]LimitOverride `json:"limits,omitempty"`
+
+	ValidationOptions
+}
+
+type CalculationRequest3 struct {
+	Basis string  `json:"basis"`
+	H     float64 `json:"h"`
+	C     float64 `json:"c"`
+	S     float64 `json:"s"`
+	N     float64 `json:"n"`
+	O     float64 `json:"o"`
+	WP    float64 `json:"wp"`
+	AP    float64 `json:"ap"`
+
+	// Маса, на яку задано зольність ap: working або dry; за замовчуванням dry
+	// для складу на сухій масі і working для інших
+	AshBasis string `json:"ashBasis,omitempty"`
+
+	ValidationOptions
+}
+
+type CalculationRequest4 struct {
+	CalculationRequest1
+	Alpha float64 `json:"alpha,omitempty"`
+}
+
+// Компонент суміші: тверде паливо за робочою масою або мазут за горючою масою
+type BlendComponent struct {
+	Share float64              `json:"share"`
+	Solid *CalculationRequest1 `json:"solid,omitempty"`
+	Oil   *CalculationRequest2 `json:"oil,omitempty"`
+}
+
+type CalculationRequest5 struct {
+	ShareBasis string           `json:"shareBasis"`
+	Fuels      []BlendComponent `json:"fuels"`
+}
+
+type CalculationRequest6 struct {
+	GasComposition
+
+	Alpha float64 `json:"alpha,omitempty"`
+
+	ValidationOptions
+}
+
+// Маси палива: робоча, суха, горюча
+const (
+	basisWorking     = "working"
+	basisDry         = "dry"
+	basisCombustible = "combustible"
+)
+
+var fuelBases = []string{basisWorking, basisDry, basisCombustible}
+
+// Частка кожної маси відносно робочої маси палива
+func basisShares(wp, ap float64) map[string]float64 {
+	return map[string]float64{
+		basisWorking:     1,
+		basisDry:         (100 - wp) / 100,
+		basisCombustible: (100 - wp - ap) / 100,
+	}
+}
+
+// Матриця коефіцієнтів перерахунку з маси from у масу to
+func conversionFactors(wp, ap float64) map[string]map[string]float64 {
+	shares := basisShares(wp, ap)
+	factors := make(map[string]map[string]float64, len(fuelBases))
+	for _, from := range fuelBases {
+		factors[from] = make(map[string]float64, len(fuelBases))
+		for _, to := range fuelBases {
+			factors[from][to] = shares[from] / shares[to]
+		}
+	}
+	return factors
+}
+
+// Кореляція для вищої теплоти згоряння робочої маси, МДж/кг
+type HeatingValueCorrelation struct {
+	Name   string
+	Higher func(req CalculationRequest1) float64
+}
+
+// Поправка на приховану теплоту пароутворення вологи палива та вологи від згоряння водню, МДж/кг
+func latentHeatCorrection(req CalculationRequest1) float64 {
+	return 0.025 * (9*req.HP + req.WP)
+}
+
+// Нижча теплота згоряння робочої маси за формулою Менделєєва, МДж/кг
+func mendeleevLowerHeatingValue(req CalculationRequest1) float64 {
+	return (339*req.CP + 1030*req.HP - 108.8*(req.OP-req.SP) - 25*req.WP) / 1000
+}
+
+var heatingValueCorrelations = map[string]HeatingValueCorrelation{
+	"mendeleev": {
+		Name: "Mendeleev",
+		Higher: func(req CalculationRequest1) float64 {
+			return mendeleevLowerHeatingValue(req) + latentHeatCorrection(req)
+		},
+	},
+	"dulong": {
+		Name: "Dulong",
+		Higher: func(req CalculationRequest1) float64 {
+			return 0.3383*req.CP + 1.443*(req.HP-req.OP/8) + 0.0942*req.SP
+		},
+	},
+	"boie": {
+		Name: "Boie",
+		Higher: func(req CalculationRequest1) float64 {
+			return 0.3516*req.CP + 1.16225*req.HP - 0.1109*req.OP + 0.0628*req.NP + 0.10465*req.SP
+		},
+	},
+	"channiwala-parikh": {
+		Name: "Channiwala–Parikh",
+		Higher: func(req CalculationRequest1) float64 {
+			return 0.3491*req.CP + 1.1783*req.HP + 0.1005*req.SP - 0.1034*req.OP - 0.0151*req.NP - 0.0211*req.AP
+		},
+	},
+}
+
+// Теплота згоряння за обраними кореляціями та розкид між ними
+func compareHeatingValues(req CalculationRequest1) (gin.H, gin.H, error) {
+	values := make(gin.H, len(req.Correlations))
+	minLower, maxLower := math.Inf(1), math.Inf(-1)
+	minHigher, maxHigher := math.Inf(1), math.Inf(-1)
+
+	for _, key := range req.Correlations {
+		correlation, ok := heatingValueCorrelations[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("Невідома кореляція теплоти згоряння: %s", key)
+		}
+
+		higher := correlation.Higher(req)
+		lower := higher - latentHeatCorrection(req)
+		values[key] = gin.H{
+			"name":   correlation.Name,
+			"lower":  lower,
+			"higher": higher,
+		}
+
+		minLower, maxLower = math.Min(minLower, lower), math.Max(maxLower, lower)
+		minHigher, maxHigher = math.Min(minHigher, higher), math.Max(maxHigher, higher)
+	}
+
+	spread := gin.H{
+		"lower":  maxLower - minLower,
+		"higher": maxHigher - minHigher,
+	}
+	return values, spread, nil
+}
+
+// Теоретично необхідний об'єм повітря та об'єми продуктів згоряння робочої маси, м³/кг
+func flueGasVolumes(req CalculationRequest1, alpha float64) (float64, map[string]float64) {
+	theoreticalAir := 0.0889*(req.CP+0.375*req.SP) + 0.265*req.HP - 0.0333*req.OP
+	actualAir := alpha * theoreticalAir
+
+	return theoreticalAir, map[string]float64{
+		"co2": 1.866 * req.CP / 100,
+		"so2": 0.7 * req.SP / 100,
+		"n2":  0.79*actualAir + 0.8*req.NP/100,
+		"h2o": 0.111*req.HP + 0.0124*req.WP + 0.0161*actualAir,
+		"o2":  0.21 * (alpha - 1) * theoreticalAir,
+	}
+}
+
+// Межі показника якості: при Fail < Warn гіршим вважається менше значення
+type IndicatorLimit struct {
+	Warn float64 `json:"warn"`
+	Fail float64 `json:"fail"`
+}
+
+func (limit IndicatorLimit) lowerIsWorse() bool {
+	return limit.Fail < limit.Warn
+}
+
+// Межі, задані в запиті; незадані поля беруться з типових меж показника
+type LimitOverride struct {
+	Warn *float64 `json:"warn,omitempty"`
+	Fail *float64 `json:"fail,omitempty"`
+}
+
+var defaultFuelOilLimits = map[string]IndicatorLimit{
+	"sulfur":              {Warn: 2.0, Fail: 3.5},
+	"vanadium":            {Warn: 50, Fail: 150},
+	"sodium":              {Warn: 20, Fail: 50},
+	"vanadiumSodiumRatio": {Warn: 5, Fail: 3},
+	"so3DewPoint":         {Warn: 120, Fail: 140},
+}
+
+var fuelOilIndicatorUnits = map[string]string{
+	"sulfur":              "%",
+	"vanadium":            "mg/kg",
+	"sodium":              "mg/kg",
+	"vanadiumSodiumRatio": "",
+	"so3DewPoint":         "°C",
+}
+
+// Межі показника з урахуванням заданих у запиті; напрямок (чи гіршим є більше значення)
+// визначається типовими межами і не може бути змінений
+func fuelOilLimit(key string, override LimitOverride) (IndicatorLimit, error) {
+	limit := defaultFuelOilLimits[key]
+	lowerIsWorse := limit.lowerIsWorse()
+	if override.Warn != nil {
+		limit.Warn = *override.Warn
+	}
+	if override.Fail != nil {
+		limit.Fail = *override.Fail
+	}
+
+	if lowerIsWorse && limit.Fail > limit.Warn {
+		return limit, fmt.Errorf("Для показника %s межа fail повинна бути не більшою за warn", key)
+	}
+	if !lowerIsWorse && limit.Fail < limit.Warn {
+		return limit, fmt.Errorf("Для показника %s межа fail повинна бути не меншою за warn", key)
+	}
+	return limit, nil
+}
+
+func indicatorStatus(value float64, limit IndicatorLimit, lowerIsWorse bool) string {
+	if lowerIsWorse {
+		value, limit = -value, IndicatorLimit{Warn: -limit.Warn, Fail: -limit.Fail}
+	}
+	switch {
+	case value >= limit.Fail:
+		return "fail"
+	case value >= limit.Warn:
+		return "warn"
+	default:
+		return "pass"
+	}
+}
+
+// Кількість кДж в одній ккал
+const kJPerKcal = 4.1868
+
+// Температура конденсації водяної пари за рівнянням Антуана, °C
+func waterDewPoint(partialPressure float64) float64 {
+	pressureMmHg := partialPressure / 0.133322
+	return 1730.63/(8.07131-math.Log10(pressureMmHg)) - 233.426
+}
+
+// Температура точки роси димових газів з урахуванням SO3 за нормативним методом
+func so3DewPoint(req CalculationRequest1, qR, alpha float64) (float64, float64) {
+	_, flueGas := flueGasVolumes(req, alpha)
+	var flueGasTotal float64
+	for _, volume := range flueGas {
+		flueGasTotal += volume
+	}
+	tCondensation := waterDewPoint(flueGas["h2o"] / flueGasTotal * 101.325)
+
+	// Коефіцієнт β зростає від 121 при α ≤ 1.2 до 129 при α ≥ 1.4
+	beta := 121 + 8*math.Min(math.Max((alpha-1.2)/0.2, 0), 1)
+
+	// Приведені сірчистість і зольність нормативного методу: 10³·S/Q з Q у ккал/кг
+	qKcal := qR * 1000 / kJPerKcal
+	reducedSulfur := 1000 * req.SP / qKcal
+	reducedAsh := 1000 * req.AP / qKcal
+
+	return tCondensation + beta*math.Cbrt(reducedSulfur)/math.Pow(1.05, reducedAsh), tCondensation
+}
+
+// Перерахунок горючої маси мазуту на робочу масу та нижча теплота згоряння робочої маси
+func fuelOilWorkingMass(req CalculationRequest2) (CalculationRequest1, float64) {
+	krs := (100 - req.W - req.A) / 100
+	working := CalculationRequest1{SolidComposition: SolidComposition{
+		HP: req.H * krs,
+		CP: req.C * krs,
+		SP: req.S * krs,
+		OP: req.O * krs,
+		WP: req.W,
+		AP: req.A,
+	}}
+	return working, req.QFO*krs - 0.025*req.W
+}
+
+// Характеристики компонента газу за нормальних умов на 1 м³ компонента
+type GasComponent struct {
+	Density float64 // кг/м³
+	Lower   float64 // нижча теплота згоряння, МДж/м³
+	Higher  float64 // вища теплота згоряння, МДж/м³
+	Oxygen  float64 // потреба в кисні, м³/м³
+	CO2     float64 // утворення CO2, м³/м³
+	SO2     float64 // утворення SO2, м³/м³
+	H2O     float64 // утворення H2O, м³/м³
+	N2      float64 // перехід у N2, м³/м³
+}
+
+var gasComponents = map[string]GasComponent{
+	"ch4":  {Density: 0.717, Lower: 35.82, Higher: 39.82, Oxygen: 2, CO2: 1, H2O: 2},
+	"c2h6": {Density: 1.356, Lower: 63.75, Higher: 70.31, Oxygen: 3.5, CO2: 2, H2O: 3},
+	"c3h8": {Density: 2.020, Lower: 91.25, Higher: 101.21, Oxygen: 5, CO2: 3, H2O: 4},
+	"h2":   {Density: 0.090, Lower: 10.79, Higher: 12.75, Oxygen: 0.5, H2O: 1},
+	"co":   {Density: 1.250, Lower: 12.64, Higher: 12.64, Oxygen: 0.5, CO2: 1},
+	"co2":  {Density: 1.977, CO2: 1},
+	"n2":   {Density: 1.251, N2: 1},
+	"h2s":  {Density: 1.539, Lower: 23.38, Higher: 25.35, Oxygen: 1.5, SO2: 1, H2O: 1},
+}
+
+// Густина повітря за нормальних умов, кг/м³
+const airDensity = 1.293
+
+func (req GasComposition) components() map[string]float64 {
+	return map[string]float64{
+		"ch4":  req.CH4,
+		"c2h6": req.C2H6,
+		"c3h8": req.C3H8,
+		"h2":   req.H2,
+		"co":   req.CO,
+		"co2":  req.CO2,
+		"n2":   req.N2,
+		"h2s":  req.H2S,
+	}
 }
 
 func calculate1(c *gin.Context) {
 	var req CalculationRequest1
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	err := bindWithFuel(c, &req, func(fuel Fuel) bool {
+		if fuel.Solid != nil {
+			req.SolidComposition = *fuel.Solid
+		}
+		return fuel.Solid != nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	total := req.HP + req.CP + req.SP + req.NP + req.OP + req.WP + req.AP
-	if total != 100 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Сума всіх компонентів повинна складати 100%"})
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, err)
 		return
 	}
 
@@ -62,11 +413,16 @@ func calculate1(c *gin.Context) {
 		"op": req.OP * krg,
 	}
 
-	qph := (339*req.CP + 1030*req.HP - 108.8*(req.OP-req.SP) - 25*req.WP) / 1000
+	qph := mendeleevLowerHeatingValue(req)
 	qch := (qph + 0.025*req.WP) * krs
 	qgh := (qph + 0.025*req.WP) * krg
 
-	c.JSON(http.StatusOK, gin.H{
+	// Вища теплота згоряння робочої, сухої та горючої маси
+	qpv := qph + latentHeatCorrection(req)
+	qcv := qpv * krs
+	qgv := qpv * krg
+
+	response := gin.H{
 		"krs":                        krs,
 		"krg":                        krg,
 		"dryMassComposition":         dryMassComposition,
@@ -74,43 +430,432 @@ func calculate1(c *gin.Context) {
 		"qph":                        qph,
 		"qch":                        qch,
 		"qgh":                        qgh,
-	})
+		"qpv":                        qpv,
+		"qcv":                        qcv,
+		"qgv":                        qgv,
+		"units": gin.H{
+			"composition":  "%",
+			"heatingValue": "MJ/kg",
+		},
+	}
+
+	if len(req.Correlations) > 0 {
+		heatingValues, spread, err := compareHeatingValues(req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		response["heatingValues"] = heatingValues
+		response["heatingValueSpread"] = spread
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 func calculate2(c *gin.Context) {
 	var req CalculationRequest2
+	err := bindWithFuel(c, &req, func(fuel Fuel) bool {
+		if fuel.Oil != nil {
+			req.OilComposition = *fuel.Oil
+		}
+		return fuel.Oil != nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	working, qR := fuelOilWorkingMass(req)
+	if qR <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нижча теплота згоряння робочої маси повинна бути додатною"})
+		return
+	}
+	vWork := req.V * (100 - req.W) / 100
+	naWork := req.Na * (100 - req.W) / 100
+
+	// Коефіцієнт надлишку повітря для котлів на мазуті за замовчуванням
+	if req.Alpha == 0 {
+		req.Alpha = 1.1
+	}
+	if req.Alpha < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Коефіцієнт надлишку повітря повинен бути не меншим за 1"})
+		return
+	}
+
+	dewPoint, waterDew := so3DewPoint(working, qR, req.Alpha)
+
+	values := map[string]float64{
+		"sulfur":      working.SP,
+		"vanadium":    vWork,
+		"sodium":      naWork,
+		"so3DewPoint": dewPoint,
+	}
+	if naWork > 0 {
+		values["vanadiumSodiumRatio"] = vWork / naWork
+	}
+
+	for key := range req.Limits {
+		if _, ok := defaultFuelOilLimits[key]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Невідомий показник якості: " + key})
+			return
+		}
+	}
+
+	quality := make(gin.H, len(values))
+	for key, value := range values {
+		limit, err := fuelOilLimit(key, req.Limits[key])
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		quality[key] = gin.H{
+			"value":  value,
+			"unit":   fuelOilIndicatorUnits[key],
+			"warn":   limit.Warn,
+			"fail":   limit.Fail,
+			"status": indicatorStatus(value, limit, defaultFuelOilLimits[key].lowerIsWorse()),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"composition": gin.H{
+			"CP":  working.CP,
+			"HP":  working.HP,
+			"SP":  working.SP,
+			"OP":  working.OP,
+			"AP":  req.A,
+			"VP":  vWork,
+			"NaP": naWork,
+		},
+		"qR":            qR,
+		"waterDewPoint": waterDew,
+		"quality":       quality,
+	})
+}
+
+func calculate3(c *gin.Context) {
+	var req CalculationRequest3
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	krs := (100 - req.W - req.A) / 100
-	hWork := req.H * krs
-	cWork := req.C * krs
-	sWork := req.S * krs
-	oWork := req.O * krs
-	vWork := req.V * (100 - req.W) / 100
-	qR := req.QFO*krs - 0.025*req.W
+	if req.Basis == "" {
+		req.Basis = basisWorking
+	}
+	if req.Basis != basisWorking && req.Basis != basisDry && req.Basis != basisCombustible {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Невідома маса палива: " + req.Basis})
+		return
+	}
+
+	if req.AshBasis == "" {
+		req.AshBasis = basisWorking
+		if req.Basis == basisDry {
+			req.AshBasis = basisDry
+		}
+	}
+	switch req.AshBasis {
+	case basisWorking:
+	case basisDry:
+		// Зольність сухої маси перераховується на робочу масу
+		req.AP *= (100 - req.WP) / 100
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Зольність задається на робочу (working) або суху (dry) масу"})
+		return
+	}
+	if req.WP < 0 || req.AP < 0 || req.WP+req.AP >= 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Вологість і зольність повинні бути невід'ємними, а їх сума меншою за 100%"})
+		return
+	}
+
+	factors := conversionFactors(req.WP, req.AP)
+
+	// Горюча частина, перерахована на горючу масу, повинна складати 100%
+	k := factors[req.Basis][basisCombustible]
+	organic, compositionErr := validateComposition(map[string]float64{
+		"h": req.H * k,
+		"c": req.C * k,
+		"s": req.S * k,
+		"n": req.N * k,
+		"o": req.O * k,
+	}, req.ValidationOptions)
+	if compositionErr != nil {
+		c.JSON(http.StatusBadRequest, compositionErr)
+		return
+	}
+
+	compositions := make(map[string]map[string]float64, len(fuelBases))
+	for _, basis := range fuelBases {
+		composition := make(map[string]float64, len(organic)+2)
+		for key, value := range organic {
+			composition[key] = value * factors[basisCombustible][basis]
+		}
+		compositions[basis] = composition
+	}
+	compositions[basisWorking]["w"] = req.WP
+	compositions[basisWorking]["a"] = req.AP
+	compositions[basisDry]["a"] = req.AP * factors[basisWorking][basisDry]
+
+	c.JSON(http.StatusOK, gin.H{
+		"basis":        req.Basis,
+		"ashBasis":     req.AshBasis,
+		"factors":      factors,
+		"compositions": compositions,
+	})
+}
+
+func calculate4(c *gin.Context) {
+	var req CalculationRequest4
+	err := bindWithFuel(c, &req, func(fuel Fuel) bool {
+		if fuel.Solid != nil {
+			req.SolidComposition = *fuel.Solid
+		}
+		return fuel.Solid != nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.CalculationRequest1.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+	// Без alpha розраховується стехіометричне горіння
+	if req.Alpha == 0 {
+		req.Alpha = 1
+	}
+	if req.Alpha < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Коефіцієнт надлишку повітря повинен бути не меншим за 1"})
+		return
+	}
+
+	theoreticalAir, flueGas := flueGasVolumes(req.CalculationRequest1, req.Alpha)
+	var flueGasTotal float64
+	for _, volume := range flueGas {
+		flueGasTotal += volume
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"theoreticalAir": theoreticalAir,
+		"actualAir":      req.Alpha * theoreticalAir,
+		"flueGas":        flueGas,
+		"flueGasTotal":   flueGasTotal,
+		"flueGasDry":     flueGasTotal - flueGas["h2o"],
+		"units": gin.H{
+			"air":     "m3/kg",
+			"flueGas": "m3/kg",
+		},
+	})
+}
+
+func calculate5(c *gin.Context) {
+	var req CalculationRequest5
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if req.ShareBasis == "" {
+		req.ShareBasis = "mass"
+	}
+	if req.ShareBasis != "mass" && req.ShareBasis != "energy" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Частки палив задаються за масою (mass) або за енергією (energy)"})
+		return
+	}
+	if len(req.Fuels) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Суміш повинна містити хоча б одне паливо"})
+		return
+	}
+
+	// Робоча маса та нижча теплота згоряння кожного палива
+	compositions := make([]CalculationRequest1, len(req.Fuels))
+	heatingValues := make([]float64, len(req.Fuels))
+	weights := make([]float64, len(req.Fuels))
+	var totalWeight float64
+	for i, fuel := range req.Fuels {
+		switch {
+		case fuel.Solid != nil && fuel.Oil == nil:
+			if err := fuel.Solid.validate(); err != nil {
+				err.Message = fmt.Sprintf("Паливо %d: %s", i+1, err.Message)
+				c.JSON(http.StatusBadRequest, err)
+				return
+			}
+			compositions[i] = *fuel.Solid
+			heatingValues[i] = mendeleevLowerHeatingValue(*fuel.Solid)
+		case fuel.Oil != nil && fuel.Solid == nil:
+			if err := fuel.Oil.validate(); err != nil {
+				err.Message = fmt.Sprintf("Паливо %d: %s", i+1, err.Message)
+				c.JSON(http.StatusBadRequest, err)
+				return
+			}
+			compositions[i], heatingValues[i] = fuelOilWorkingMass(*fuel.Oil)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Паливо %d: потрібно задати або solid, або oil", i+1)})
+			return
+		}
+
+		if fuel.Share <= 0 || heatingValues[i] <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Паливо %d: частка та теплота згоряння повинні бути додатними", i+1)})
+			return
+		}
+
+		// Енергетична частка перераховується в масову через теплоту згоряння
+		weights[i] = fuel.Share
+		if req.ShareBasis == "energy" {
+			weights[i] = fuel.Share / heatingValues[i]
+		}
+		totalWeight += weights[i]
+	}
+
+	var blend CalculationRequest1
+	var qph float64
+	massShares := make([]float64, len(req.Fuels))
+	energyShares := make([]float64, len(req.Fuels))
+	for i, composition := range compositions {
+		massShares[i] = weights[i] / totalWeight
+		blend.HP += massShares[i] * composition.HP
+		blend.CP += massShares[i] * composition.CP
+		blend.SP += massShares[i] * composition.SP
+		blend.NP += massShares[i] * composition.NP
+		blend.OP += massShares[i] * composition.OP
+		blend.WP += massShares[i] * composition.WP
+		blend.AP += massShares[i] * composition.AP
+		qph += massShares[i] * heatingValues[i]
+	}
+	for i := range energyShares {
+		energyShares[i] = massShares[i] * heatingValues[i] / qph
+	}
 
 	c.JSON(http.StatusOK, gin.H{
+		"massShares":   massShares,
+		"energyShares": energyShares,
 		"composition": gin.H{
-			"CP": cWork,
-			"HP": hWork,
-			"SP": sWork,
-			"OP": oWork,
-			"AP": req.A,
-			"VP": vWork,
+			"hp": blend.HP,
+			"cp": blend.CP,
+			"sp": blend.SP,
+			"np": blend.NP,
+			"op": blend.OP,
+			"wp": blend.WP,
+			"ap": blend.AP,
+		},
+		"qph":      qph,
+		"qpv":      qph + latentHeatCorrection(blend),
+		"moisture": blend.WP,
+		"ash":      blend.AP,
+		"units": gin.H{
+			"composition":  "%",
+			"heatingValue": "MJ/kg",
+		},
+	})
+}
+
+func calculate6(c *gin.Context) {
+	var req CalculationRequest6
+	err := bindWithFuel(c, &req, func(fuel Fuel) bool {
+		if fuel.Gas != nil {
+			req.GasComposition = *fuel.Gas
+		}
+		return fuel.Gas != nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	components, compositionErr := validateComposition(req.components(), req.ValidationOptions)
+	if compositionErr != nil {
+		c.JSON(http.StatusBadRequest, compositionErr)
+		return
+	}
+	// Без alpha розраховується стехіометричне горіння
+	if req.Alpha == 0 {
+		req.Alpha = 1
+	}
+	if req.Alpha < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Коефіцієнт надлишку повітря повинен бути не меншим за 1"})
+		return
+	}
+
+	var density, lower, higher, oxygen float64
+	var co2, so2, h2o, n2 float64
+	for key, percent := range components {
+		share := percent / 100
+		component := gasComponents[key]
+		density += share * component.Density
+		lower += share * component.Lower
+		higher += share * component.Higher
+		oxygen += share * component.Oxygen
+		co2 += share * component.CO2
+		so2 += share * component.SO2
+		h2o += share * component.H2O
+		n2 += share * component.N2
+	}
+
+	// Відносна густина за повітрям та число Воббе
+	relativeDensity := density / airDensity
+	theoreticalAir := oxygen / 0.21
+	actualAir := req.Alpha * theoreticalAir
+
+	flueGas := map[string]float64{
+		"co2": co2,
+		"so2": so2,
+		"n2":  0.79*actualAir + n2,
+		"h2o": h2o + 0.0161*actualAir,
+		"o2":  0.21 * (req.Alpha - 1) * theoreticalAir,
+	}
+	var flueGasTotal float64
+	for _, volume := range flueGas {
+		flueGasTotal += volume
+	}
+	flueGasComposition := make(map[string]float64, len(flueGas))
+	for key, volume := range flueGas {
+		flueGasComposition[key] = volume / flueGasTotal * 100
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"density":            density,
+		"relativeDensity":    relativeDensity,
+		"lowerHeatingValue":  lower,
+		"higherHeatingValue": higher,
+		"lowerWobbeIndex":    lower / math.Sqrt(relativeDensity),
+		"higherWobbeIndex":   higher / math.Sqrt(relativeDensity),
+		"theoreticalAir":     theoreticalAir,
+		"actualAir":          actualAir,
+		"flueGas":            flueGas,
+		"flueGasTotal":       flueGasTotal,
+		"flueGasComposition": flueGasComposition,
+		"units": gin.H{
+			"density":      "kg/m3",
+			"heatingValue": "MJ/m3",
+			"wobbeIndex":   "MJ/m3",
+			"air":          "m3/m3",
+			"flueGas":      "m3/m3",
+			"composition":  "%",
 		},
-		"qR": qR,
 	})
 }
 
 func main() {
+	cataloguePath := os.Getenv("FUEL_CATALOGUE_PATH")
+	if cataloguePath == "" {
+		cataloguePath = "fuels.json"
+	}
+	var err error
+	fuelCatalogue, err = NewCatalogue(cataloguePath, seedFuels, func(fuel Fuel) string { return fuel.ID })
+	if err != nil {
+		log.Fatalf("Fuel catalogue loading error: %v", err)
+	}
+
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Content-Type"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -118,6 +863,16 @@ func main() {
 
 	r.POST("/api/calculate1", calculate1)
 	r.POST("/api/calculate2", calculate2)
+	r.POST("/api/calculate3", calculate3)
+	r.POST("/api/calculate4", calculate4)
+	r.POST("/api/calculate5", calculate5)
+	r.POST("/api/calculate6", calculate6)
+
+	r.GET("/api/fuels", listFuels)
+	r.GET("/api/fuels/:id", getFuel)
+	r.POST("/api/fuels", createFuel)
+	r.PUT("/api/fuels/:id", updateFuel)
+	r.DELETE("/api/fuels/:id", deleteFuel)
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("Server startup error: %v", err)
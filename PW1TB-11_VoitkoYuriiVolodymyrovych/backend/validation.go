@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Допустиме відхилення суми компонентів від 100% за замовчуванням
+const defaultCompositionTolerance = 0.05
+
+// Параметри перевірки складу палива, що передаються разом із запитом
+type ValidationOptions struct {
+	Tolerance float64 `json:"tolerance,omitempty"`
+	Normalize bool    `json:"normalize,omitempty"`
+}
+
+type ComponentIssue struct {
+	Component string  `json:"component"`
+	Value     float64 `json:"value"`
+	Reason    string  `json:"reason"`
+}
+
+// Помилка складу палива з діагностикою по кожному компоненту
+type CompositionError struct {
+	Message    string             `json:"error"`
+	Components map[string]float64 `json:"components"`
+	Sum        float64            `json:"sum"`
+	Deviation  float64            `json:"deviation,omitempty"`
+	Tolerance  float64            `json:"tolerance,omitempty"`
+	Issues     []ComponentIssue   `json:"issues,omitempty"`
+}
+
+func (e *CompositionError) Error() string {
+	return e.Message
+}
+
+// Перевірка, що компоненти лежать у межах 0..100% і в сумі дають 100% з заданою точністю;
+// при Normalize склад пропорційно приводиться до 100%
+func validateComposition(components map[string]float64, opts ValidationOptions) (map[string]float64, *CompositionError) {
+	tolerance := opts.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultCompositionTolerance
+	}
+
+	keys := make([]string, 0, len(components))
+	for key := range components {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sum float64
+	var issues []ComponentIssue
+	for _, key := range keys {
+		value := components[key]
+		sum += value
+		switch {
+		case value < 0:
+			issues = append(issues, ComponentIssue{Component: key, Value: value, Reason: "negative"})
+		case value > 100:
+			issues = append(issues, ComponentIssue{Component: key, Value: value, Reason: "out of range"})
+		}
+	}
+
+	deviation := sum - 100
+	compositionError := &CompositionError{
+		Components: components,
+		Sum:        sum,
+		Deviation:  deviation,
+		Tolerance:  tolerance,
+		Issues:     issues,
+	}
+
+	if len(issues) > 0 {
+		compositionError.Message = "Компоненти складу повинні бути в межах від 0 до 100%"
+		return nil, compositionError
+	}
+	if opts.Normalize && sum > 0 {
+		normalized := make(map[string]float64, len(components))
+		for key, value := range components {
+			normalized[key] = value * 100 / sum
+		}
+		return normalized, nil
+	}
+	if math.Abs(deviation) > tolerance {
+		compositionError.Message = fmt.Sprintf("Сума всіх компонентів повинна складати 100%% (отримано %.4f%%, відхилення %.4f%%)", sum, deviation)
+		return nil, compositionError
+	}
+	return components, nil
+}
+
+func (req SolidComposition) components() map[string]float64 {
+	return map[string]float64{
+		"hp": req.HP,
+		"cp": req.CP,
+		"sp": req.SP,
+		"np": req.NP,
+		"op": req.OP,
+		"wp": req.WP,
+		"ap": req.AP,
+	}
+}
+
+func (req *SolidComposition) setComponents(components map[string]float64) {
+	req.HP = components["hp"]
+	req.CP = components["cp"]
+	req.SP = components["sp"]
+	req.NP = components["np"]
+	req.OP = components["op"]
+	req.WP = components["wp"]
+	req.AP = components["ap"]
+}
+
+// Перевірка робочої маси твердого палива з можливою нормалізацією на місці
+func (req *CalculationRequest1) validate() *CompositionError {
+	components, err := validateComposition(req.components(), req.ValidationOptions)
+	if err != nil {
+		return err
+	}
+	req.setComponents(components)
+
+	if req.WP+req.AP >= 100 {
+		return &CompositionError{
+			Message:    "Вологість і зольність повинні бути невід'ємними, а їх сума меншою за 100%",
+			Components: map[string]float64{"wp": req.WP, "ap": req.AP},
+			Sum:        req.WP + req.AP,
+		}
+	}
+	return nil
+}
+
+// Перевірка горючої маси мазуту з можливою нормалізацією на місці
+func (req *CalculationRequest2) validate() *CompositionError {
+	combustible := map[string]float64{"h": req.H, "c": req.C, "s": req.S, "o": req.O}
+	components, err := validateComposition(combustible, req.ValidationOptions)
+	if err != nil {
+		return err
+	}
+	req.H, req.C, req.S, req.O = components["h"], components["c"], components["s"], components["o"]
+
+	if req.W < 0 || req.A < 0 || req.W+req.A >= 100 {
+		return &CompositionError{
+			Message:    "Вологість і зольність повинні бути невід'ємними, а їх сума меншою за 100%",
+			Components: map[string]float64{"w": req.W, "a": req.A},
+			Sum:        req.W + req.A,
+		}
+	}
+	return nil
+}
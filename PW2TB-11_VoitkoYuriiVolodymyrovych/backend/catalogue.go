@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Довідник з окремою копією для кожної команди, що зберігається у JSON-файлі
+type Catalogue[T any] struct {
+	mu    sync.Mutex
+	path  string
+	seed  []T
+	id    func(T) string
+	teams map[string]map[string]T
+}
+
+func NewCatalogue[T any](path string, seed []T, id func(T) string) (*Catalogue[T], error) {
+	catalogue := &Catalogue[T]{path: path, seed: seed, id: id, teams: map[string]map[string]T{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return catalogue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &catalogue.teams); err != nil {
+		return nil, err
+	}
+	return catalogue, nil
+}
+
+// Записи команди; при першому зверненні команда отримує копію початкових даних
+func (fc *Catalogue[T]) team(name string) map[string]T {
+	items, ok := fc.teams[name]
+	if !ok {
+		items = make(map[string]T, len(fc.seed))
+		for _, item := range fc.seed {
+			items[fc.id(item)] = item
+		}
+		fc.teams[name] = items
+	}
+	return items
+}
+
+func (fc *Catalogue[T]) List(team string) []T {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	items := make([]T, 0, len(fc.team(team)))
+	for _, item := range fc.team(team) {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return fc.id(items[i]) < fc.id(items[j]) })
+	return items
+}
+
+func (fc *Catalogue[T]) Get(team, id string) (T, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	item, ok := fc.team(team)[id]
+	return item, ok
+}
+
+// Додавання нового запису; false, якщо запис з таким id уже є
+func (fc *Catalogue[T]) Create(team string, item T) (bool, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	items := fc.team(team)
+	id := fc.id(item)
+	if _, ok := items[id]; ok {
+		return false, nil
+	}
+	items[id] = item
+	if err := fc.save(); err != nil {
+		delete(items, id)
+		return true, err
+	}
+	return true, nil
+}
+
+// Заміна наявного запису; false, якщо запису з таким id немає
+func (fc *Catalogue[T]) Update(team string, item T) (bool, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	items := fc.team(team)
+	id := fc.id(item)
+	previous, ok := items[id]
+	if !ok {
+		return false, nil
+	}
+	items[id] = item
+	if err := fc.save(); err != nil {
+		items[id] = previous
+		return true, err
+	}
+	return true, nil
+}
+
+// Видалення запису; false, якщо запису з таким id немає
+func (fc *Catalogue[T]) Delete(team, id string) (bool, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	items := fc.team(team)
+	previous, ok := items[id]
+	if !ok {
+		return false, nil
+	}
+	delete(items, id)
+	if err := fc.save(); err != nil {
+		items[id] = previous
+		return true, err
+	}
+	return true, nil
+}
+
+func (fc *Catalogue[T]) save() error {
+	data, err := json.MarshalIndent(fc.teams, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := fc.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fc.path)
+}
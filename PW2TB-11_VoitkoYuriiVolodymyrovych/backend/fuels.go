@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"net/http"
+)
+
+// Паливо з довідника з характеристиками, що визначають викиди
+type Fuel struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	Qir  float64 `json:"Q_i_r"`
+	Ar   float64 `json:"A_r"`
+	Avun float64 `json:"a_vun"`
+	Gvun float64 `json:"G_vun"`
+	Sr   float64 `json:"S_r"`
+	Cr   float64 `json:"C_r"`
+}
+
+// Початковий вміст довідника, який отримує кожна команда
+var seedFuels = []Fuel{
+	{ID: "donetsk-coal-gr", Name: "Донецьке вугілля марки ГР", Qir: 20.47, Ar: 25.20, Avun: 0.8, Gvun: 1.5, Sr: 2.85, Cr: 52.49},
+	{ID: "mazut-high-sulfur", Name: "Мазут високосірчистий", Qir: 39.48, Ar: 0.15, Avun: 1.0, Gvun: 0, Sr: 2.45, Cr: 83.66},
+	{ID: "natural-gas", Name: "Природний газ", Qir: 33.08, Ar: 0, Avun: 0, Gvun: 0, Sr: 0, Cr: 53.5},
+}
+
+var fuelCatalogue *Catalogue[Fuel]
+
+func requestTeam(c *gin.Context) string {
+	return c.DefaultQuery("team", "default")
+}
+
+func validateFuel(fuel Fuel) error {
+	if fuel.ID == "" || fuel.Name == "" {
+		return errors.New("Паливо повинно мати id та name")
+	}
+	if fuel.Qir <= 0 {
+		return errors.New("Теплота згоряння палива повинна бути додатною")
+	}
+	return nil
+}
+
+// Заповнення запиту складом палива з довідника за fuelId;
+// поля, передані в тілі запиту, перевизначають значення з довідника
+func bindWithFuel(c *gin.Context, req any, fill func(Fuel)) error {
+	var ref struct {
+		FuelID string `json:"fuelId"`
+	}
+	if err := c.ShouldBindBodyWith(&ref, binding.JSON); err != nil {
+		return err
+	}
+
+	if ref.FuelID != "" {
+		fuel, ok := fuelCatalogue.Get(requestTeam(c), ref.FuelID)
+		if !ok {
+			return errors.New("Паливо не знайдено в довіднику: " + ref.FuelID)
+		}
+		fill(fuel)
+	}
+
+	return c.ShouldBindBodyWith(req, binding.JSON)
+}
+
+func listFuels(c *gin.Context) {
+	c.JSON(http.StatusOK, fuelCatalogue.List(requestTeam(c)))
+}
+
+func getFuel(c *gin.Context) {
+	fuel, ok := fuelCatalogue.Get(requestTeam(c), c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Паливо не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, fuel)
+}
+
+func createFuel(c *gin.Context) {
+	var fuel Fuel
+	if err := c.ShouldBindJSON(&fuel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateFuel(fuel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := fuelCatalogue.Create(requestTeam(c), fuel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Паливо з таким id вже існує"})
+		return
+	}
+	c.JSON(http.StatusCreated, fuel)
+}
+
+func updateFuel(c *gin.Context) {
+	var fuel Fuel
+	if err := c.ShouldBindJSON(&fuel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	fuel.ID = c.Param("id")
+	if err := validateFuel(fuel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := fuelCatalogue.Update(requestTeam(c), fuel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Паливо не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, fuel)
+}
+
+func deleteFuel(c *gin.Context) {
+	deleted, err := fuelCatalogue.Delete(requestTeam(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Паливо не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Річна інвентаризація викидів підприємства з кількома енергоблоками
+type Inventory struct {
+	ID    string      `json:"id"`
+	Plant string      `json:"plant"`
+	Year  int         `json:"year"`
+	Units []PlantUnit `json:"units"`
+}
+
+type PlantUnit struct {
+	Name  string     `json:"name"`
+	Fuels []UnitFuel `json:"fuels"`
+}
+
+// Паливо енергоблока: витрата палива за місяцями та параметри розрахунку, як у calculate1;
+// незадані (відсутні в запиті) характеристики палива беруться з довідника за fuelId,
+// тож задане нульове значення, наприклад S_r для знесірченого палива, має пріоритет
+type UnitFuel struct {
+	Name    string    `json:"name,omitempty"`
+	FuelID  string    `json:"fuelId"`
+	Monthly []float64 `json:"monthly"`
+
+	Qir  *float64 `json:"Q_i_r,omitempty"`
+	Ar   *float64 `json:"A_r,omitempty"`
+	Avun *float64 `json:"a_vun,omitempty"`
+	Gvun *float64 `json:"G_vun,omitempty"`
+	Sr   *float64 `json:"S_r,omitempty"`
+	Cr   *float64 `json:"C_r,omitempty"`
+
+	// Золоуловлення: єдиний ККД або ступені очищення з фракційним складом пилу
+	EtaZy  float64            `json:"eta_z_y,omitempty"`
+	KtvS   float64            `json:"k_tv_s,omitempty"`
+	Stages []CleaningStage    `json:"stages,omitempty"`
+	PSD    []ParticleFraction `json:"psd,omitempty"`
+
+	// Зв'язування SO2 золою та сіркоочищення, утворення NOx, CO та недопал вуглецю
+	EtaSO2Ash    float64 `json:"eta_SO2_ash,omitempty"`
+	EtaSO2Desulf float64 `json:"eta_SO2_desulf,omitempty"`
+	KNOxRef      float64 `json:"k_NOx_ref,omitempty"`
+	FBurner      float64 `json:"f_burner,omitempty"`
+	Load         float64 `json:"load,omitempty"`
+	EtaNOx       float64 `json:"eta_NOx,omitempty"`
+	KCO          float64 `json:"k_CO,omitempty"`
+	EpsC         float64 `json:"eps_C,omitempty"`
+}
+
+func (fuel UnitFuel) key() string {
+	if fuel.Name != "" {
+		return fuel.Name
+	}
+	return fuel.FuelID
+}
+
+// Валові викиди за рік, енергоблоками, паливами та місяцями, т
+type InventoryReport struct {
+	ID     string                        `json:"id"`
+	Plant  string                        `json:"plant"`
+	Year   int                           `json:"year"`
+	Total  map[string]float64            `json:"total"`
+	Units  map[string]map[string]float64 `json:"units"`
+	Fuels  map[string]map[string]float64 `json:"fuels"`
+	Months []map[string]float64          `json:"months"`
+	Unit   string                        `json:"unit"`
+}
+
+var inventoryCatalogue *Catalogue[Inventory]
+
+func validateInventory(inventory Inventory) error {
+	if inventory.ID == "" || inventory.Plant == "" {
+		return errors.New("Інвентаризація повинна мати id та plant")
+	}
+	if len(inventory.Units) == 0 {
+		return errors.New("Підприємство повинно мати хоча б один енергоблок")
+	}
+	for _, unit := range inventory.Units {
+		if unit.Name == "" {
+			return errors.New("Енергоблок повинен мати name")
+		}
+		for _, fuel := range unit.Fuels {
+			if fuel.key() == "" {
+				return fmt.Errorf("Енергоблок %s: паливо повинно мати name або fuelId", unit.Name)
+			}
+			if len(fuel.Monthly) != 12 {
+				return fmt.Errorf("Енергоблок %s, паливо %s: потрібно задати витрату за 12 місяців", unit.Name, fuel.key())
+			}
+			for month, consumption := range fuel.Monthly {
+				if consumption < 0 {
+					return fmt.Errorf("Енергоблок %s, паливо %s: витрата за місяць %d не може бути від'ємною", unit.Name, fuel.key(), month+1)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Параметри розрахунку палива енергоблока: характеристики з довідника (якщо паливо задано
+// за fuelId), перевизначені заданими для енергоблока
+func (unitFuel UnitFuel) request(fuel *Fuel) CalculationRequest1 {
+	req := CalculationRequest1{
+		EtaZy:        unitFuel.EtaZy,
+		KtvS:         unitFuel.KtvS,
+		Stages:       unitFuel.Stages,
+		PSD:          unitFuel.PSD,
+		EtaSO2Ash:    unitFuel.EtaSO2Ash,
+		EtaSO2Desulf: unitFuel.EtaSO2Desulf,
+		KNOxRef:      unitFuel.KNOxRef,
+		FBurner:      unitFuel.FBurner,
+		Load:         unitFuel.Load,
+		EtaNOx:       unitFuel.EtaNOx,
+		KCO:          unitFuel.KCO,
+		EpsC:         unitFuel.EpsC,
+	}
+	if fuel != nil {
+		req.Qir, req.Ar, req.Avun = fuel.Qir, fuel.Ar, fuel.Avun
+		req.Gvun, req.Sr, req.Cr = fuel.Gvun, fuel.Sr, fuel.Cr
+	}
+	for _, field := range []struct {
+		value    *float64
+		override *float64
+	}{
+		{&req.Qir, unitFuel.Qir},
+		{&req.Ar, unitFuel.Ar},
+		{&req.Avun, unitFuel.Avun},
+		{&req.Gvun, unitFuel.Gvun},
+		{&req.Sr, unitFuel.Sr},
+		{&req.Cr, unitFuel.Cr},
+	} {
+		if field.override != nil {
+			*field.value = *field.override
+		}
+	}
+	return req
+}
+
+func addEmissions(total map[string]float64, emissions map[string]float64) {
+	for pollutant, value := range emissions {
+		total[pollutant] += value
+	}
+}
+
+func inventoryReport(team string, inventory Inventory) (InventoryReport, error) {
+	total := map[string]float64{}
+	units := map[string]map[string]float64{}
+	fuels := map[string]map[string]float64{}
+	months := make([]map[string]float64, 12)
+	for i := range months {
+		months[i] = map[string]float64{}
+	}
+
+	for _, unit := range inventory.Units {
+		if units[unit.Name] == nil {
+			units[unit.Name] = map[string]float64{}
+		}
+		for _, unitFuel := range unit.Fuels {
+			var catalogueFuel *Fuel
+			if unitFuel.FuelID != "" {
+				fuel, ok := fuelCatalogue.Get(team, unitFuel.FuelID)
+				if !ok {
+					return InventoryReport{}, errors.New("Паливо не знайдено в довіднику: " + unitFuel.FuelID)
+				}
+				catalogueFuel = &fuel
+			}
+			req := unitFuel.request(catalogueFuel)
+			if req.Qir <= 0 {
+				return InventoryReport{}, fmt.Errorf("Енергоблок %s, паливо %s: теплота згоряння повинна бути додатною", unit.Name, unitFuel.key())
+			}
+
+			indices, _, err := emissionIndices(req)
+			if err != nil {
+				return InventoryReport{}, fmt.Errorf("Енергоблок %s, паливо %s: %v", unit.Name, unitFuel.key(), err)
+			}
+
+			key := unitFuel.key()
+			if fuels[key] == nil {
+				fuels[key] = map[string]float64{}
+			}
+			for month, consumption := range unitFuel.Monthly {
+				emissions := make(map[string]float64, len(indices))
+				for pollutant, k := range indices {
+					emissions[pollutant] = grossEmission(k, req.Qir, consumption)
+				}
+				addEmissions(total, emissions)
+				addEmissions(units[unit.Name], emissions)
+				addEmissions(fuels[key], emissions)
+				addEmissions(months[month], emissions)
+			}
+		}
+	}
+
+	return InventoryReport{
+		ID:     inventory.ID,
+		Plant:  inventory.Plant,
+		Year:   inventory.Year,
+		Total:  total,
+		Units:  units,
+		Fuels:  fuels,
+		Months: months,
+		Unit:   "t",
+	}, nil
+}
+
+func listInventories(c *gin.Context) {
+	c.JSON(http.StatusOK, inventoryCatalogue.List(requestTeam(c)))
+}
+
+func getInventory(c *gin.Context) {
+	inventory, ok := inventoryCatalogue.Get(requestTeam(c), c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Інвентаризацію не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, inventory)
+}
+
+func createInventory(c *gin.Context) {
+	var inventory Inventory
+	if err := c.ShouldBindJSON(&inventory); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateInventory(inventory); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := inventoryCatalogue.Create(requestTeam(c), inventory)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Інвентаризація з таким id вже існує"})
+		return
+	}
+	c.JSON(http.StatusCreated, inventory)
+}
+
+func updateInventory(c *gin.Context) {
+	var inventory Inventory
+	if err := c.ShouldBindJSON(&inventory); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	inventory.ID = c.Param("id")
+	if err := validateInventory(inventory); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := inventoryCatalogue.Update(requestTeam(c), inventory)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Інвентаризацію не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, inventory)
+}
+
+func deleteInventory(c *gin.Context) {
+	deleted, err := inventoryCatalogue.Delete(requestTeam(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Інвентаризацію не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func getInventoryReport(c *gin.Context) {
+	team := requestTeam(c)
+	inventory, ok := inventoryCatalogue.Get(team, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Інвентаризацію не знайдено"})
+		return
+	}
+
+	report, err := inventoryReport(team, inventory)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"math"
+	"net/http"
+)
+
+// Гранично допустимий викид: концентрація, мг/нм³, та/або показник емісії, г/ГДж
+type EmissionLimit struct {
+	Concentration float64 `json:"mgNm3,omitempty"`
+	Specific      float64 `json:"gGJ,omitempty"`
+}
+
+// Граничні викиди для категорії установок; FlueGasVolume — питомий об'єм сухих
+// димових газів за нормованого вмісту O2, нм³/ГДж, для перерахунку мг/нм³ у г/ГДж
+type LimitSet struct {
+	ID            string                   `json:"id"`
+	Name          string                   `json:"name"`
+	FlueGasVolume float64                  `json:"flueGasVolume"`
+	Limits        map[string]EmissionLimit `json:"limits"`
+}
+
+// Початкові граничні викиди за категоріями установок (IED 2010/75/EU, додаток V)
+var seedLimitSets = []LimitSet{
+	{ID: "solid-50-100", Name: "Тверде паливо, 50–100 МВт", FlueGasVolume: 350, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 30}, "SO2": {Concentration: 400}, "NOx": {Concentration: 300},
+	}},
+	{ID: "solid-100-300", Name: "Тверде паливо, 100–300 МВт", FlueGasVolume: 350, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 25}, "SO2": {Concentration: 250}, "NOx": {Concentration: 200},
+	}},
+	{ID: "solid-300", Name: "Тверде паливо, понад 300 МВт", FlueGasVolume: 350, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 20}, "SO2": {Concentration: 200}, "NOx": {Concentration: 200},
+	}},
+	{ID: "liquid-50-100", Name: "Рідке паливо, 50–100 МВт", FlueGasVolume: 280, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 30}, "SO2": {Concentration: 350}, "NOx": {Concentration: 450},
+	}},
+	{ID: "liquid-100-300", Name: "Рідке паливо, 100–300 МВт", FlueGasVolume: 280, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 25}, "SO2": {Concentration: 250}, "NOx": {Concentration: 200},
+	}},
+	{ID: "liquid-300", Name: "Рідке паливо, понад 300 МВт", FlueGasVolume: 280, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 20}, "SO2": {Concentration: 200}, "NOx": {Concentration: 150},
+	}},
+	{ID: "gas", Name: "Природний газ", FlueGasVolume: 270, Limits: map[string]EmissionLimit{
+		"solid": {Concentration: 5}, "SO2": {Concentration: 35}, "NOx": {Concentration: 100}, "CO": {Concentration: 100},
+	}},
+}
+
+var limitCatalogue *Catalogue[LimitSet]
+
+func validateLimitSet(set LimitSet) error {
+	if set.ID == "" || set.Name == "" {
+		return errors.New("Категорія повинна мати id та name")
+	}
+	if set.FlueGasVolume <= 0 {
+		return errors.New("Питомий об'єм димових газів повинен бути додатним")
+	}
+	for pollutant, limit := range set.Limits {
+		if limit.Concentration <= 0 && limit.Specific <= 0 {
+			return errors.New("Для " + pollutant + " потрібно задати mgNm3 або gGJ")
+		}
+	}
+	return nil
+}
+
+// Речовини, показники емісії яких визначаються вхідними даними запиту. Незадані
+// характеристики палива (без fuelId) та k_NOx_ref, k_CO дорівнюють 0, тому викид
+// відповідної речовини не розраховано і порівнювати його з граничним не можна
+func evaluatedPollutants(c *gin.Context) (map[string]bool, error) {
+	var given struct {
+		FuelID  string   `json:"fuelId"`
+		Ar      *float64 `json:"A_r"`
+		Avun    *float64 `json:"a_vun"`
+		Sr      *float64 `json:"S_r"`
+		Cr      *float64 `json:"C_r"`
+		KNOxRef *float64 `json:"k_NOx_ref"`
+		KCO     *float64 `json:"k_CO"`
+	}
+	if err := c.ShouldBindBodyWith(&given, binding.JSON); err != nil {
+		return nil, err
+	}
+
+	fuel := given.FuelID != ""
+	return map[string]bool{
+		"solid": fuel || (given.Ar != nil && given.Avun != nil),
+		"SO2":   fuel || given.Sr != nil,
+		"CO2":   fuel || given.Cr != nil,
+		"NOx":   given.KNOxRef != nil,
+		"CO":    given.KCO != nil,
+	}, nil
+}
+
+// Перевірка дотримання граничних викидів: запас до межі та ККД очищення,
+// необхідний для її дотримання, відносно викиду без очищення; для речовин,
+// викид яких не розраховано, висновок "not evaluated"
+func complianceReport(req CalculationRequest1, indices map[string]float64, evaluated map[string]bool, set LimitSet) gin.H {
+	flueGasVolume := set.FlueGasVolume
+	if req.FlueGasVolume > 0 {
+		flueGasVolume = req.FlueGasVolume
+	}
+
+	uncontrolledReq := req
+	uncontrolledReq.EtaZy, uncontrolledReq.Stages = 0, nil
+	uncontrolledReq.EtaSO2Desulf, uncontrolledReq.EtaNOx = 0, 0
+	uncontrolled, _, _ := emissionIndices(uncontrolledReq)
+
+	report := make(gin.H, len(set.Limits))
+	for pollutant, limit := range set.Limits {
+		specific := limit.Specific
+		if specific == 0 {
+			specific = limit.Concentration * flueGasVolume / 1000
+		}
+
+		actual, ok := indices[pollutant]
+		if !ok || !evaluated[pollutant] {
+			report[pollutant] = gin.H{
+				"limit":              specific,
+				"limitConcentration": specific * 1000 / flueGasVolume,
+				"verdict":            "not evaluated",
+			}
+			continue
+		}
+		margin := specific - actual
+		verdict := "comply"
+		if margin < 0 {
+			verdict = "exceed"
+		}
+
+		// Сталу складову k_tv_s очищення не зменшує
+		var floor float64
+		if pollutant == "solid" {
+			floor = req.KtvS
+		}
+		requiredEfficiency := 0.0
+		if reducible := uncontrolled[pollutant] - floor; reducible > 0 {
+			requiredEfficiency = math.Max(1-(specific-floor)/reducible, 0)
+		}
+
+		report[pollutant] = gin.H{
+			"limit":              specific,
+			"limitConcentration": specific * 1000 / flueGasVolume,
+			"emissionIndex":      actual,
+			"concentration":      actual * 1000 / flueGasVolume,
+			"margin":             margin,
+			"marginPercent":      margin / specific * 100,
+			"verdict":            verdict,
+			"requiredEfficiency": requiredEfficiency,
+			"achievable":         requiredEfficiency < 1,
+		}
+	}
+	return report
+}
+
+func listLimitSets(c *gin.Context) {
+	c.JSON(http.StatusOK, limitCatalogue.List(requestTeam(c)))
+}
+
+func getLimitSet(c *gin.Context) {
+	set, ok := limitCatalogue.Get(requestTeam(c), c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Категорію не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
+
+func createLimitSet(c *gin.Context) {
+	var set LimitSet
+	if err := c.ShouldBindJSON(&set); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateLimitSet(set); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := limitCatalogue.Create(requestTeam(c), set)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Категорія з таким id вже існує"})
+		return
+	}
+	c.JSON(http.StatusCreated, set)
+}
+
+func updateLimitSet(c *gin.Context) {
+	var set LimitSet
+	if err := c.ShouldBindJSON(&set); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	set.ID = c.Param("id")
+	if err := validateLimitSet(set); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := limitCatalogue.Update(requestTeam(c), set)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Категорію не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
+
+func deleteLimitSet(c *gin.Context) {
+	deleted, err := limitCatalogue.Delete(requestTeam(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Категорію не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -1,52 +1,535 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"sort"
+	"strconv"
 	"time"
 )
 
 type CalculationRequest1 struct {
-	Qir     float64 `json:"Q_i_r"`
-	Avun    float64 `json:"a_vun"`
-	Ar      float64 `json:"A_r"`
-	Gvun    float64 `json:"G_vun"`
-	EtaZy   float64 `json:"eta_z_y"`
-	KtvS    float64 `json:"k_tv_s"`
-	B       float64 `json:"B"`
+	Qir   float64 `json:"Q_i_r"`
+	Avun  float64 `json:"a_vun"`
+	Ar    float64 `json:"A_r"`
+	Gvun  float64 `json:"G_vun"`
+	EtaZy float64 `json:"eta_z_y"`
+	KtvS  float64 `json:"k_tv_s"`
+	B     float64 `json:"B"`
+
+	// Вміст сірки та вуглецю в робочій масі, % (для газу — кг на 100 м³)
+	Sr float64 `json:"S_r"`
+	Cr float64 `json:"C_r"`
+
+	EtaSO2Ash    float64 `json:"eta_SO2_ash"`
+	EtaSO2Desulf float64 `json:"eta_SO2_desulf"`
+	KNOxRef      float64 `json:"k_NOx_ref"`
+	FBurner      float64 `json:"f_burner"`
+	Load         float64 `json:"load"` // навантаження котла відносно номінального
+	EtaNOx       float64 `json:"eta_NOx"`
+	KCO          float64 `json:"k_CO"`
+	EpsC         float64 `json:"eps_C"`
+
+	// Ступені золоуловлення; якщо задані, замінюють єдиний ККД eta_z_y
+	Stages []CleaningStage    `json:"stages,omitempty"`
+	PSD    []ParticleFraction `json:"psd,omitempty"`
+
+	// Категорія установки для перевірки граничних викидів
+	Category      string  `json:"category,omitempty"`
+	FlueGasVolume float64 `json:"flueGasVolume,omitempty"`
+}
+
+// Фракція пилу: розмір частинок, мкм, та масова частка
+type ParticleFraction struct {
+	Size  float64 `json:"size"`
+	Share float64 `json:"share"`
+}
+
+// Ступінь очищення з загальним або фракційним (для кожної фракції psd) ККД;
+// без efficiency використовується типовий ККД, efficiency 0 — ступінь не працює
+type CleaningStage struct {
+	Type                 string    `json:"type"`
+	Efficiency           *float64  `json:"efficiency,omitempty"`
+	FractionalEfficiency []float64 `json:"fractionalEfficiency"`
+}
+
+type StageResult struct {
+	Type              string             `json:"type"`
+	Efficiency        float64            `json:"efficiency"`
+	OverallEfficiency float64            `json:"overallEfficiency"`
+	EmissionIndex     float64            `json:"emissionIndex"`
+	GrossEmission     float64            `json:"grossEmission"`
+	OutletPSD         []ParticleFraction `json:"outletPsd,omitempty"`
+}
+
+// Типові ККД золоуловлювачів, якщо ККД ступеня не задано
+var defaultStageEfficiency = map[string]float64{
+	"cyclone":  0.85,
+	"esp":      0.99,
+	"bag":      0.995,
+	"scrubber": 0.95,
+}
+
+// Послідовне проходження пилу через ступені очищення з урахуванням зміни
+// фракційного складу після кожного ступеня
+func applyCleaningStages(stages []CleaningStage, psd []ParticleFraction) ([]StageResult, error) {
+	hasPSD := len(psd) > 0
+	if !hasPSD {
+		psd = []ParticleFraction{{Share: 1}}
+	}
+	mass := make([]float64, len(psd))
+	var inlet float64
+	for i, fraction := range psd {
+		if fraction.Share < 0 {
+			return nil, fmt.Errorf("Частка фракції %d не може бути від'ємною", i+1)
+		}
+		mass[i] = fraction.Share
+		inlet += fraction.Share
+	}
+	if inlet <= 0 {
+		return nil, errors.New("Фракційний склад пилу порожній")
+	}
+
+	results := make([]StageResult, len(stages))
+	for i, stage := range stages {
+		efficiencies := stage.FractionalEfficiency
+		if len(efficiencies) == 0 {
+			var efficiency float64
+			if stage.Efficiency != nil {
+				efficiency = *stage.Efficiency
+			} else {
+				var ok bool
+				if efficiency, ok = defaultStageEfficiency[stage.Type]; !ok {
+					return nil, fmt.Errorf("Ступінь %d: для золоуловлювача типу %q потрібно задати ККД", i+1, stage.Type)
+				}
+			}
+			efficiencies = make([]float64, len(mass))
+			for j := range efficiencies {
+				efficiencies[j] = efficiency
+			}
+		} else if !hasPSD || len(efficiencies) != len(psd) {
+			return nil, fmt.Errorf("Ступінь %d: фракційний ККД потрібно задати для кожної фракції psd", i+1)
+		}
+
+		var before, after float64
+		for j := range mass {
+			if efficiencies[j] < 0 || efficiencies[j] > 1 {
+				return nil, fmt.Errorf("Ступінь %d: ККД повинен бути в межах від 0 до 1", i+1)
+			}
+			before += mass[j]
+			mass[j] *= 1 - efficiencies[j]
+			after += mass[j]
+		}
+
+		// Після ступеня з ККД 1 пилу не лишається, і наступні ступені нічого не вловлюють
+		efficiency := 0.0
+		if before > 0 {
+			efficiency = 1 - after/before
+		}
+		results[i] = StageResult{
+			Type:              stage.Type,
+			Efficiency:        efficiency,
+			OverallEfficiency: 1 - after/inlet,
+		}
+		if hasPSD && after > 0 {
+			outlet := make([]ParticleFraction, len(psd))
+			for j := range psd {
+				outlet[j] = ParticleFraction{Size: psd[j].Size, Share: mass[j] / after}
+			}
+			results[i].OutletPSD = outlet
+		}
+	}
+	return results, nil
+}
+
+// Показник емісії твердих частинок при загальному ККД золоуловлення, г/ГДж
+func solidEmissionIndex(req CalculationRequest1, etaZy float64) float64 {
+	return (1e6/req.Qir)*(req.Avun*(req.Ar/(100-req.Gvun))*(1-etaZy)) + req.KtvS
+}
+
+// Викиди задаються явно за періодами або беруться з інвентаризації
+// з розбивкою за місяцями (month) чи кварталами (quarter)
+type CalculationRequest2 struct {
+	Year        int              `json:"year"`
+	Periods     []EmissionPeriod `json:"periods"`
+	InventoryID string           `json:"inventoryId"`
+	Breakdown   string           `json:"breakdown"`
+}
+
+// Базові значення, як у calculate1, та діапазони невизначеності вхідних параметрів;
+// method: montecarlo, tornado або порожній для обох
+type CalculationRequest3 struct {
+	CalculationRequest1
+
+	Uncertainty map[string]UncertaintyRange `json:"uncertainty"`
+	Method      string                      `json:"method,omitempty"`
+	Samples     int                         `json:"samples,omitempty"`
+	Seed        int64                       `json:"seed,omitempty"`
+	Percentiles []float64                   `json:"percentiles,omitempty"`
+}
+
+// Валовий викид забруднюючої речовини за показником емісії, т
+func grossEmission(k, qir, b float64) float64 {
+	return 1e-6 * k * qir * b
+}
+
+// Показники емісії SO2, NOx, CO та CO2, г/ГДж
+func pollutantEmissionIndices(req CalculationRequest1) map[string]float64 {
+	fBurner, load, epsC := req.FBurner, req.Load, req.EpsC
+	if fBurner == 0 {
+		fBurner = 1
+	}
+	if load == 0 {
+		load = 1
+	}
+	if epsC == 0 {
+		epsC = 1
+	}
+
+	return map[string]float64{
+		"SO2": (1e6 / req.Qir) * 2 * req.Sr / 100 * (1 - req.EtaSO2Ash) * (1 - req.EtaSO2Desulf),
+		"NOx": req.KNOxRef * fBurner * math.Sqrt(load) * (1 - req.EtaNOx),
+		"CO":  req.KCO,
+		"CO2": (1e6 / req.Qir) * 44.0 / 12.0 * req.Cr / 100 * epsC,
+	}
+}
+
+// Показники емісії всіх забруднюючих речовин, г/ГДж, та результати ступенів золоуловлення
+func emissionIndices(req CalculationRequest1) (map[string]float64, []StageResult, error) {
+	var stages []StageResult
+	if len(req.Stages) > 0 {
+		var err error
+		stages, err = applyCleaningStages(req.Stages, req.PSD)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range stages {
+			stages[i].EmissionIndex = solidEmissionIndex(req, stages[i].OverallEfficiency)
+			stages[i].GrossEmission = grossEmission(stages[i].EmissionIndex, req.Qir, req.B)
+		}
+		req.EtaZy = stages[len(stages)-1].OverallEfficiency
+	}
+
+	indices := pollutantEmissionIndices(req)
+	indices["solid"] = solidEmissionIndex(req, req.EtaZy)
+	return indices, stages, nil
 }
 
 func calculate1(c *gin.Context) {
 	var req CalculationRequest1
+	err := bindWithFuel(c, &req, func(fuel Fuel) {
+		req.Qir = fuel.Qir
+		req.Ar = fuel.Ar
+		req.Avun = fuel.Avun
+		req.Gvun = fuel.Gvun
+		req.Sr = fuel.Sr
+		req.Cr = fuel.Cr
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Qir <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Теплота згоряння Q_i_r повинна бути додатною"})
+		return
+	}
+
+	indices, stages, err := emissionIndices(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	kTv := indices["solid"]
+
+	E_tv := grossEmission(kTv, req.Qir, req.B)
+
+	pollutants := make(gin.H, len(indices))
+	for pollutant, k := range indices {
+		pollutants[pollutant] = gin.H{
+			"emissionIndex": k,
+			"grossEmission": grossEmission(k, req.Qir, req.B),
+		}
+	}
+
+	response := gin.H{
+		"emissionIndex": kTv,
+		"grossEmission": E_tv,
+		"pollutants":    pollutants,
+		"units": gin.H{
+			"emissionIndex": "g/GJ",
+			"grossEmission": "t",
+		},
+	}
+	if stages != nil {
+		response["stages"] = stages
+		response["overallEfficiency"] = stages[len(stages)-1].OverallEfficiency
+	}
+	if req.Category != "" {
+		set, ok := limitCatalogue.Get(requestTeam(c), req.Category)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Категорію не знайдено: " + req.Category})
+			return
+		}
+		evaluated, err := evaluatedPollutants(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+		response["compliance"] = complianceReport(req, indices, evaluated, set)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Екологічний податок за викиди в розрізі речовин і періодів
+func calculate2(c *gin.Context) {
+	var req CalculationRequest2
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	kTv := (1e6 / req.Qir) * (req.Avun * (req.Ar / (100 - req.Gvun)) * (1 - req.EtaZy)) + req.KtvS
+	team := requestTeam(c)
+	if req.InventoryID != "" {
+		periods, year, err := inventoryPeriods(team, req.InventoryID, req.Breakdown)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Periods = periods
+		if req.Year == 0 {
+			req.Year = year
+		}
+	}
+	if len(req.Periods) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Потрібно задати periods або inventoryId"})
+		return
+	}
+
+	rates, ok := taxRateCatalogue.Get(team, strconv.Itoa(req.Year))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Ставки податку на %d рік не знайдено", req.Year)})
+		return
+	}
+
+	pollutantTotals := map[string]float64{}
+	unrated := map[string]bool{}
+	var total float64
+	periods := make([]gin.H, len(req.Periods))
+	for i, period := range req.Periods {
+		taxes := make(map[string]float64, len(period.Emissions))
+		var periodTotal float64
+		for pollutant, emission := range period.Emissions {
+			rate, ok := rates.Rates[pollutant]
+			if !ok {
+				unrated[pollutant] = true
+				continue
+			}
+			taxes[pollutant] = emission * rate
+			pollutantTotals[pollutant] += taxes[pollutant]
+			periodTotal += taxes[pollutant]
+		}
+		total += periodTotal
+		periods[i] = gin.H{
+			"period": period.Period,
+			"taxes":  taxes,
+			"total":  periodTotal,
+		}
+	}
 
-	E_tv := 1e-6 * kTv * req.Qir * req.B
+	unratedPollutants := make([]string, 0, len(unrated))
+	for pollutant := range unrated {
+		unratedPollutants = append(unratedPollutants, pollutant)
+	}
+	sort.Strings(unratedPollutants)
 
 	c.JSON(http.StatusOK, gin.H{
-		"emissionIndex": kTv,
-		"grossEmission": E_tv,
+		"year":              req.Year,
+		"rates":             rates.Rates,
+		"periods":           periods,
+		"pollutants":        pollutantTotals,
+		"total":             total,
+		"unratedPollutants": unratedPollutants,
+		"currency":          "UAH",
 	})
 }
 
+// Невизначеність показника емісії та валового викиду твердих частинок
+func calculate3(c *gin.Context) {
+	var req CalculationRequest3
+	err := bindWithFuel(c, &req, func(fuel Fuel) {
+		req.Qir = fuel.Qir
+		req.Ar = fuel.Ar
+		req.Avun = fuel.Avun
+		req.Gvun = fuel.Gvun
+		req.Sr = fuel.Sr
+		req.Cr = fuel.Cr
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch req.Method {
+	case "", "montecarlo", "tornado":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Метод повинен бути montecarlo або tornado"})
+		return
+	}
+	if req.Qir <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Теплота згоряння Q_i_r повинна бути додатною"})
+		return
+	}
+	inputs, err := uncertainInputs(req.CalculationRequest1, req.Uncertainty)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := req.Uncertainty["eta_z_y"]; ok && len(req.Stages) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Діапазон eta_z_y не можна поєднувати зі ступенями золоуловлення stages"})
+		return
+	}
+
+	kTv, E_tv, err := solidEmission(req.CalculationRequest1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	response := gin.H{
+		"base": gin.H{
+			"emissionIndex": kTv,
+			"grossEmission": E_tv,
+		},
+		"units": gin.H{
+			"emissionIndex": "g/GJ",
+			"grossEmission": "t",
+		},
+	}
+
+	if req.Method != "tornado" {
+		samples := req.Samples
+		if samples == 0 {
+			samples = defaultSamples
+		}
+		if samples < 2 || samples > maxSamples {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Кількість реалізацій samples повинна бути від 2 до %d", maxSamples)})
+			return
+		}
+		percentiles := req.Percentiles
+		if len(percentiles) == 0 {
+			percentiles = defaultPercentiles
+		}
+		for _, p := range percentiles {
+			if p < 0 || p > 100 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Процентилі повинні бути в межах від 0 до 100"})
+				return
+			}
+		}
+		seed := req.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		response["monteCarlo"], err = monteCarlo(req.CalculationRequest1, inputs, samples, seed, percentiles)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Method != "montecarlo" {
+		response["tornado"], err = tornado(req.CalculationRequest1, inputs)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func main() {
+	cataloguePath := os.Getenv("FUEL_CATALOGUE_PATH")
+	if cataloguePath == "" {
+		cataloguePath = "fuels.json"
+	}
+	var err error
+	fuelCatalogue, err = NewCatalogue(cataloguePath, seedFuels, func(fuel Fuel) string { return fuel.ID })
+	if err != nil {
+		log.Fatalf("Fuel catalogue loading error: %v", err)
+	}
+
+	inventoryPath := os.Getenv("INVENTORY_PATH")
+	if inventoryPath == "" {
+		inventoryPath = "inventories.json"
+	}
+	inventoryCatalogue, err = NewCatalogue(inventoryPath, nil, func(inventory Inventory) string { return inventory.ID })
+	if err != nil {
+		log.Fatalf("Inventory loading error: %v", err)
+	}
+
+	limitsPath := os.Getenv("LIMITS_PATH")
+	if limitsPath == "" {
+		limitsPath = "limits.json"
+	}
+	limitCatalogue, err = NewCatalogue(limitsPath, seedLimitSets, func(set LimitSet) string { return set.ID })
+	if err != nil {
+		log.Fatalf("Emission limits loading error: %v", err)
+	}
+
+	taxRatesPath := os.Getenv("TAX_RATES_PATH")
+	if taxRatesPath == "" {
+		taxRatesPath = "tax_rates.json"
+	}
+	taxRateCatalogue, err = NewCatalogue(taxRatesPath, seedTaxRates, taxRatesID)
+	if err != nil {
+		log.Fatalf("Tax rates loading error: %v", err)
+	}
+
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Content-Type"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	r.POST("/api/calculate1", calculate1)
+	r.POST("/api/calculate2", calculate2)
+	r.POST("/api/calculate3", calculate3)
+
+	r.GET("/api/fuels", listFuels)
+	r.GET("/api/fuels/:id", getFuel)
+	r.POST("/api/fuels", createFuel)
+	r.PUT("/api/fuels/:id", updateFuel)
+	r.DELETE("/api/fuels/:id", deleteFuel)
+
+	r.GET("/api/inventories", listInventories)
+	r.GET("/api/inventories/:id", getInventory)
+	r.GET("/api/inventories/:id/report", getInventoryReport)
+	r.POST("/api/inventories", createInventory)
+	r.PUT("/api/inventories/:id", updateInventory)
+	r.DELETE("/api/inventories/:id", deleteInventory)
+
+	r.GET("/api/limits", listLimitSets)
+	r.GET("/api/limits/:id", getLimitSet)
+	r.POST("/api/limits", createLimitSet)
+	r.PUT("/api/limits/:id", updateLimitSet)
+	r.DELETE("/api/limits/:id", deleteLimitSet)
+
+	r.GET("/api/tax-rates", listTaxRates)
+	r.GET("/api/tax-rates/:year", getTaxRates)
+	r.POST("/api/tax-rates", createTaxRates)
+	r.PUT("/api/tax-rates/:year", updateTaxRates)
+	r.DELETE("/api/tax-rates/:year", deleteTaxRates)
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("Server startup error: %v", err)
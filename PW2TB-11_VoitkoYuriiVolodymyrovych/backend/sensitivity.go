@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// Кількість реалізацій Монте-Карло за замовчуванням та максимально допустима
+const (
+	defaultSamples = 10000
+	maxSamples     = 200000
+)
+
+// Найменша допустима теплота згоряння в аналізі невизначеності, МДж/кг (нижча за будь-яке
+// реальне паливо); обмежує вибірку, щоб показник емісії лишався скінченним
+const minHeatingValue = 1.0
+
+// Процентилі, що повертаються, якщо їх не задано в запиті
+var defaultPercentiles = []float64{2.5, 5, 50, 95, 97.5}
+
+// Діапазон невизначеності вхідного параметра; для triangular мода — базове значення,
+// для normal межі відповідають 95% довірчому інтервалу (±1.96σ)
+type UncertaintyRange struct {
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Distribution string  `json:"distribution,omitempty"`
+}
+
+// Параметри, для яких можна задати невизначеність, та їх фізично допустимі межі
+var uncertainParameters = []struct {
+	name     string
+	field    func(req *CalculationRequest1) *float64
+	min, max float64
+}{
+	{"Q_i_r", func(req *CalculationRequest1) *float64 { return &req.Qir }, minHeatingValue, math.Inf(1)},
+	{"A_r", func(req *CalculationRequest1) *float64 { return &req.Ar }, 0, 100},
+	{"a_vun", func(req *CalculationRequest1) *float64 { return &req.Avun }, 0, 1},
+	{"G_vun", func(req *CalculationRequest1) *float64 { return &req.Gvun }, 0, 99.999},
+	{"eta_z_y", func(req *CalculationRequest1) *float64 { return &req.EtaZy }, 0, 1},
+}
+
+type uncertainInput struct {
+	name     string
+	field    func(req *CalculationRequest1) *float64
+	rng      UncertaintyRange
+	min, max float64
+}
+
+// Перевірка діапазонів невизначеності в порядку uncertainParameters
+func uncertainInputs(req CalculationRequest1, ranges map[string]UncertaintyRange) ([]uncertainInput, error) {
+	known := make(map[string]bool, len(uncertainParameters))
+	var inputs []uncertainInput
+	for _, param := range uncertainParameters {
+		known[param.name] = true
+		rng, ok := ranges[param.name]
+		if !ok {
+			continue
+		}
+		if rng.Min > rng.Max {
+			return nil, fmt.Errorf("%s: min не може перевищувати max", param.name)
+		}
+		if rng.Min < param.min || rng.Max > param.max {
+			return nil, fmt.Errorf("%s: діапазон виходить за фізично допустимі межі", param.name)
+		}
+		switch rng.Distribution {
+		case "", "uniform", "normal":
+		case "triangular":
+			if base := *param.field(&req); base < rng.Min || base > rng.Max {
+				return nil, fmt.Errorf("%s: для трикутного розподілу базове значення повинно лежати в діапазоні", param.name)
+			}
+		default:
+			return nil, fmt.Errorf("%s: невідомий розподіл %q", param.name, rng.Distribution)
+		}
+		inputs = append(inputs, uncertainInput{param.name, param.field, rng, param.min, param.max})
+	}
+	for name := range ranges {
+		if !known[name] {
+			return nil, fmt.Errorf("Невизначеність не підтримується для параметра %s", name)
+		}
+	}
+	if len(inputs) == 0 {
+		return nil, errors.New("Потрібно задати хоча б один діапазон невизначеності")
+	}
+	return inputs, nil
+}
+
+func (input uncertainInput) sample(r *rand.Rand, base float64) float64 {
+	lo, hi := input.rng.Min, input.rng.Max
+	switch input.rng.Distribution {
+	case "triangular":
+		if hi == lo {
+			return lo
+		}
+		u := r.Float64()
+		if fc := (base - lo) / (hi - lo); u < fc {
+			return lo + math.Sqrt(u*(hi-lo)*(base-lo))
+		}
+		return hi - math.Sqrt((1-u)*(hi-lo)*(hi-base))
+	case "normal":
+		value := (lo+hi)/2 + r.NormFloat64()*(hi-lo)/(2*1.96)
+		return math.Min(math.Max(value, input.min), input.max)
+	default:
+		return lo + r.Float64()*(hi-lo)
+	}
+}
+
+// Показник емісії та валовий викид твердих частинок
+func solidEmission(req CalculationRequest1) (float64, float64, error) {
+	indices, _, err := emissionIndices(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	return indices["solid"], grossEmission(indices["solid"], req.Qir, req.B), nil
+}
+
+// Процентиль відсортованої вибірки з лінійною інтерполяцією
+func percentile(sorted []float64, p float64) float64 {
+	pos := p / 100 * float64(len(sorted)-1)
+	i := int(math.Floor(pos))
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+func summarize(values []float64, percentiles []float64) gin.H {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var mean float64
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	if len(values) > 1 {
+		variance /= float64(len(values) - 1)
+	}
+
+	bands := make(map[string]float64, len(percentiles))
+	for _, p := range percentiles {
+		bands["p"+strconv.FormatFloat(p, 'f', -1, 64)] = percentile(sorted, p)
+	}
+	return gin.H{
+		"mean":        mean,
+		"std":         math.Sqrt(variance),
+		"min":         sorted[0],
+		"max":         sorted[len(sorted)-1],
+		"percentiles": bands,
+	}
+}
+
+// Ранги значень вибірки (середній ранг для однакових значень)
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		for k := start; k <= end; k++ {
+			result[order[k]] = float64(start+end)/2 + 1
+		}
+		start = end + 1
+	}
+	return result
+}
+
+// Коефіцієнт кореляції Пірсона; 0 для вибірки без розкиду
+func correlation(x, y []float64) float64 {
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+
+	var sxy, sxx, syy float64
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// Вплив вхідних параметрів за ранговою кореляцією Спірмена, за спаданням модуля
+func rankInfluence(inputs []uncertainInput, samples [][]float64, output []float64) []gin.H {
+	outputRanks := ranks(output)
+	influence := make([]gin.H, len(inputs))
+	for i, input := range inputs {
+		influence[i] = gin.H{
+			"parameter":   input.name,
+			"correlation": correlation(ranks(samples[i]), outputRanks),
+		}
+	}
+	sort.SliceStable(influence, func(a, b int) bool {
+		return math.Abs(influence[a]["correlation"].(float64)) > math.Abs(influence[b]["correlation"].(float64))
+	})
+	return influence
+}
+
+// Статистичне моделювання методом Монте-Карло
+func monteCarlo(req CalculationRequest1, inputs []uncertainInput, n int, seed int64, percentiles []float64) (gin.H, error) {
+	r := rand.New(rand.NewSource(seed))
+	samples := make([][]float64, len(inputs))
+	for i := range samples {
+		samples[i] = make([]float64, n)
+	}
+	kTv := make([]float64, n)
+	E_tv := make([]float64, n)
+
+	for s := 0; s < n; s++ {
+		trial := req
+		for i, input := range inputs {
+			field := input.field(&trial)
+			*field = input.sample(r, *field)
+			samples[i][s] = *field
+		}
+		var err error
+		if kTv[s], E_tv[s], err = solidEmission(trial); err != nil {
+			return nil, err
+		}
+	}
+
+	return gin.H{
+		"samples":       n,
+		"seed":          seed,
+		"emissionIndex": summarize(kTv, percentiles),
+		"grossEmission": summarize(E_tv, percentiles),
+		"influence": gin.H{
+			"emissionIndex": rankInfluence(inputs, samples, kTv),
+			"grossEmission": rankInfluence(inputs, samples, E_tv),
+		},
+	}, nil
+}
+
+// Аналіз чутливості «торнадо»: кожен параметр по черзі на межах діапазону,
+// решта — на базових значеннях; результати за спаданням розмаху
+func tornado(req CalculationRequest1, inputs []uncertainInput) (gin.H, error) {
+	emissionIndex := make([]gin.H, len(inputs))
+	grossEmission := make([]gin.H, len(inputs))
+	for i, input := range inputs {
+		low, high := req, req
+		*input.field(&low) = input.rng.Min
+		*input.field(&high) = input.rng.Max
+
+		kLow, eLow, err := solidEmission(low)
+		if err != nil {
+			return nil, err
+		}
+		kHigh, eHigh, err := solidEmission(high)
+		if err != nil {
+			return nil, err
+		}
+
+		emissionIndex[i] = gin.H{"parameter": input.name, "low": input.rng.Min, "high": input.rng.Max, "outputLow": kLow, "outputHigh": kHigh, "swing": math.Abs(kHigh - kLow)}
+		grossEmission[i] = gin.H{"parameter": input.name, "low": input.rng.Min, "high": input.rng.Max, "outputLow": eLow, "outputHigh": eHigh, "swing": math.Abs(eHigh - eLow)}
+	}
+
+	for _, bars := range [][]gin.H{emissionIndex, grossEmission} {
+		sort.SliceStable(bars, func(a, b int) bool {
+			return bars[a]["swing"].(float64) > bars[b]["swing"].(float64)
+		})
+	}
+	return gin.H{
+		"emissionIndex": emissionIndex,
+		"grossEmission": grossEmission,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// Ставки екологічного податку за викиди на рік, грн/т
+type TaxRates struct {
+	Year  int                `json:"year"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Початкові ставки податку за викиди в атмосферне повітря (ПКУ, ст. 243)
+var seedTaxRates = []TaxRates{
+	{Year: 2024, Rates: map[string]float64{"solid": 96.06, "SO2": 2574.43, "NOx": 2574.43, "CO": 96.06, "CO2": 30}},
+	{Year: 2025, Rates: map[string]float64{"solid": 96.06, "SO2": 2574.43, "NOx": 2574.43, "CO": 96.06, "CO2": 30}},
+}
+
+// Викиди за звітний період, т
+type EmissionPeriod struct {
+	Period    string             `json:"period"`
+	Emissions map[string]float64 `json:"emissions"`
+}
+
+var taxRateCatalogue *Catalogue[TaxRates]
+
+func taxRatesID(rates TaxRates) string {
+	return strconv.Itoa(rates.Year)
+}
+
+func validateTaxRates(rates TaxRates) error {
+	if rates.Year <= 0 {
+		return errors.New("Потрібно задати рік дії ставок")
+	}
+	for pollutant, rate := range rates.Rates {
+		if rate < 0 {
+			return errors.New("Ставка податку для " + pollutant + " не може бути від'ємною")
+		}
+	}
+	return nil
+}
+
+// Розбивка річних викидів інвентаризації на місяці або квартали
+func inventoryPeriods(team, inventoryID, breakdown string) ([]EmissionPeriod, int, error) {
+	inventory, ok := inventoryCatalogue.Get(team, inventoryID)
+	if !ok {
+		return nil, 0, errors.New("Інвентаризацію не знайдено: " + inventoryID)
+	}
+	report, err := inventoryReport(team, inventory)
+	if err != nil {
+		return nil, 0, err
+	}
+	months := report.Months
+
+	monthsPerPeriod := 3
+	switch breakdown {
+	case "", "quarter":
+	case "month":
+		monthsPerPeriod = 1
+	default:
+		return nil, 0, errors.New("Розбивка повинна бути month або quarter")
+	}
+
+	periods := make([]EmissionPeriod, 0, 12/monthsPerPeriod)
+	for start := 0; start < 12; start += monthsPerPeriod {
+		emissions := map[string]float64{}
+		for month := start; month < start+monthsPerPeriod; month++ {
+			addEmissions(emissions, months[month])
+		}
+
+		period := fmt.Sprintf("%d-%02d", inventory.Year, start+1)
+		if monthsPerPeriod == 3 {
+			period = fmt.Sprintf("%d-Q%d", inventory.Year, start/3+1)
+		}
+		periods = append(periods, EmissionPeriod{Period: period, Emissions: emissions})
+	}
+	return periods, inventory.Year, nil
+}
+
+func listTaxRates(c *gin.Context) {
+	c.JSON(http.StatusOK, taxRateCatalogue.List(requestTeam(c)))
+}
+
+func getTaxRates(c *gin.Context) {
+	rates, ok := taxRateCatalogue.Get(requestTeam(c), c.Param("year"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ставки податку не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+func createTaxRates(c *gin.Context) {
+	var rates TaxRates
+	if err := c.ShouldBindJSON(&rates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateTaxRates(rates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := taxRateCatalogue.Create(requestTeam(c), rates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ставки податку на цей рік вже існують"})
+		return
+	}
+	c.JSON(http.StatusCreated, rates)
+}
+
+func updateTaxRates(c *gin.Context) {
+	var rates TaxRates
+	if err := c.ShouldBindJSON(&rates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	rates.Year = year
+	if err := validateTaxRates(rates); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := taxRateCatalogue.Update(requestTeam(c), rates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ставки податку не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+func deleteTaxRates(c *gin.Context) {
+	deleted, err := taxRateCatalogue.Delete(requestTeam(c), c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ставки податку не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// Розподіл фактичної потужності навколо прогнозу
+type Distribution interface {
+	PDF(power float64) float64
+	CDF(power float64) float64
+}
+
+// Параметри розподілу похибки прогнозу з запиту; type: normal (за замовчуванням),
+// lognormal, student-t, beta або empirical
+type DistributionSpec struct {
+	Type string `json:"type,omitempty"`
+
+	// Кількість ступенів свободи розподілу Стьюдента
+	DF float64 `json:"df,omitempty"`
+
+	// Параметри бета-розподілу на відрізку [0, capacity]; якщо alpha і beta не задані,
+	// вони підбираються за середнім Pc та стандартним відхиленням Sigma
+	Alpha    float64 `json:"alpha,omitempty"`
+	Beta     float64 `json:"beta,omitempty"`
+	Capacity float64 `json:"capacity,omitempty"`
+
+	// Гістограма похибки прогнозу (факт мінус прогноз), МВт: межі інтервалів та частоти
+	Bins   []float64 `json:"bins,omitempty"`
+	Counts []float64 `json:"counts,omitempty"`
+}
+
+type NormalDistribution struct {
+	Mean, Sigma float64
+}
+
+func (d NormalDistribution) PDF(power float64) float64 {
+	return calculateNormalDistribution(power, d.Mean, d.Sigma)
+}
+
+func (d NormalDistribution) CDF(power float64) float64 {
+	return normalCDF(power, d.Mean, d.Sigma)
+}
+
+// Логнормальний розподіл: потужність невід'ємна, правий хвіст довший
+type LogNormalDistribution struct {
+	Mu, S float64
+}
+
+func (d LogNormalDistribution) PDF(power float64) float64 {
+	if power <= 0 {
+		return 0
+	}
+	return calculateNormalDistribution(math.Log(power), d.Mu, d.S) / power
+}
+
+func (d LogNormalDistribution) CDF(power float64) float64 {
+	if power <= 0 {
+		return 0
+	}
+	return normalCDF(math.Log(power), d.Mu, d.S)
+}
+
+// Розподіл Стьюдента зі зсувом і масштабом: важкі хвости
+type StudentTDistribution struct {
+	Location, Scale, DF float64
+}
+
+func (d StudentTDistribution) PDF(power float64) float64 {
+	t := (power - d.Location) / d.Scale
+	lgHalf, _ := math.Lgamma((d.DF + 1) / 2)
+	lg, _ := math.Lgamma(d.DF / 2)
+	return math.Exp(lgHalf-lg) / (math.Sqrt(d.DF*math.Pi) * d.Scale) * math.Pow(1+t*t/d.DF, -(d.DF+1)/2)
+}
+
+func (d StudentTDistribution) CDF(power float64) float64 {
+	t := (power - d.Location) / d.Scale
+	tail := 0.5 * regularizedIncompleteBeta(d.DF/2, 0.5, d.DF/(d.DF+t*t))
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// Бета-розподіл на відрізку [0, Capacity]: потужність обмежена нулем і встановленою потужністю
+type BetaDistribution struct {
+	Alpha, Beta, Capacity float64
+}
+
+func (d BetaDistribution) PDF(power float64) float64 {
+	x := power / d.Capacity
+	if x <= 0 || x >= 1 {
+		return 0
+	}
+	lgAB, _ := math.Lgamma(d.Alpha + d.Beta)
+	lgA, _ := math.Lgamma(d.Alpha)
+	lgB, _ := math.Lgamma(d.Beta)
+	return math.Exp(lgAB-lgA-lgB+(d.Alpha-1)*math.Log(x)+(d.Beta-1)*math.Log(1-x)) / d.Capacity
+}
+
+func (d BetaDistribution) CDF(power float64) float64 {
+	return regularizedIncompleteBeta(d.Alpha, d.Beta, power/d.Capacity)
+}
+
+// Емпіричний розподіл за гістограмою похибки прогнозу
+type EmpiricalDistribution struct {
+	Forecast float64
+	Bins     []float64
+	Density  []float64
+}
+
+func (d EmpiricalDistribution) bin(power float64) int {
+	deviation := power - d.Forecast
+	for i := range d.Density {
+		if deviation >= d.Bins[i] && deviation < d.Bins[i+1] {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d EmpiricalDistribution) PDF(power float64) float64 {
+	if i := d.bin(power); i >= 0 {
+		return d.Density[i]
+	}
+	return 0
+}
+
+func (d EmpiricalDistribution) CDF(power float64) float64 {
+	deviation := power - d.Forecast
+	var cumulative float64
+	for i, density := range d.Density {
+		if deviation < d.Bins[i] {
+			break
+		}
+		cumulative += density * (math.Min(deviation, d.Bins[i+1]) - d.Bins[i])
+	}
+	return cumulative
+}
+
+func (spec DistributionSpec) name() string {
+	if spec.Type == "" {
+		return "normal"
+	}
+	return spec.Type
+}
+
+// Побудова розподілу із середнім pc (прогноз Pc з поправкою bias) та стандартним відхиленням Sigma
+func (spec DistributionSpec) build(pc, sigma float64) (Distribution, error) {
+	if spec.Type != "empirical" && !(spec.Type == "beta" && spec.Alpha > 0 && spec.Beta > 0) && sigma <= 0 {
+		return nil, errors.New("Похибка прогнозу повинна бути додатною")
+	}
+
+	switch spec.Type {
+	case "", "normal":
+		return NormalDistribution{Mean: pc, Sigma: sigma}, nil
+
+	case "lognormal":
+		if pc <= 0 {
+			return nil, errors.New("Для логнормального розподілу середнє Pc + bias повинно бути додатним")
+		}
+		// Параметри підбираються так, щоб середнє дорівнювало Pc, а відхилення — Sigma
+		s2 := math.Log(1 + sigma*sigma/(pc*pc))
+		return LogNormalDistribution{Mu: math.Log(pc) - s2/2, S: math.Sqrt(s2)}, nil
+
+	case "student-t":
+		if spec.DF <= 0 {
+			return nil, errors.New("Для розподілу Стьюдента потрібно задати df > 0")
+		}
+		// При df > 2 масштаб підбирається так, щоб стандартне відхилення дорівнювало Sigma
+		scale := sigma
+		if spec.DF > 2 {
+			scale = sigma * math.Sqrt((spec.DF-2)/spec.DF)
+		}
+		return StudentTDistribution{Location: pc, Scale: scale, DF: spec.DF}, nil
+
+	case "beta":
+		if pc <= 0 {
+			return nil, errors.New("Для бета-розподілу середнє Pc + bias повинно бути додатним")
+		}
+		if spec.Capacity <= pc {
+			return nil, errors.New("Для бета-розподілу потрібно задати capacity більшу за Pc")
+		}
+		alpha, beta := spec.Alpha, spec.Beta
+		if alpha <= 0 || beta <= 0 {
+			mean := pc / spec.Capacity
+			variance := math.Pow(sigma/spec.Capacity, 2)
+			if variance >= mean*(1-mean) {
+				return nil, errors.New("Похибка прогнозу завелика для бета-розподілу з такою capacity")
+			}
+			common := mean*(1-mean)/variance - 1
+			alpha, beta = mean*common, (1-mean)*common
+		}
+		return BetaDistribution{Alpha: alpha, Beta: beta, Capacity: spec.Capacity}, nil
+
+	case "empirical":
+		if len(spec.Bins) < 2 || len(spec.Counts) != len(spec.Bins)-1 {
+			return nil, errors.New("Гістограма повинна мати n+1 меж інтервалів для n частот")
+		}
+		var total float64
+		for i, count := range spec.Counts {
+			if count < 0 || spec.Bins[i+1] <= spec.Bins[i] {
+				return nil, errors.New("Межі гістограми повинні зростати, а частоти бути невід'ємними")
+			}
+			total += count
+		}
+		if total <= 0 {
+			return nil, errors.New("Гістограма не містить даних")
+		}
+		density := make([]float64, len(spec.Counts))
+		for i, count := range spec.Counts {
+			density[i] = count / total / (spec.Bins[i+1] - spec.Bins[i])
+		}
+		return EmpiricalDistribution{Forecast: pc, Bins: spec.Bins, Density: density}, nil
+	}
+	return nil, errors.New("Невідомий розподіл: " + spec.Type)
+}
+
+// Регуляризована неповна бета-функція I_x(a, b) через ланцюговий дріб
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lgAB, _ := math.Lgamma(a + b)
+	lgA, _ := math.Lgamma(a)
+	lgB, _ := math.Lgamma(b)
+	front := math.Exp(lgAB - lgA - lgB + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * incompleteBetaFraction(a, b, x) / a
+	}
+	return 1 - front*incompleteBetaFraction(b, a, 1-x)/b
+}
+
+// Ланцюговий дріб для неповної бета-функції (метод Лентца)
+func incompleteBetaFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		numerator := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 / clamp(1+numerator*d)
+		c = clamp(1 + numerator/c)
+		h *= d * c
+
+		numerator = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 / clamp(1+numerator*d)
+		c = clamp(1 + numerator/c)
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
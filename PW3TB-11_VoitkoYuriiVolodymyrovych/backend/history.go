@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Мінімальна кількість записів за годину для окремої оцінки її похибки
+const minHourlyRecords = 2
+
+// Назви стовпців CSV, що розпізнаються в заголовку
+var historyColumns = map[string][]string{
+	"time":     {"timestamp", "time", "datetime", "date", "hour", "час", "дата", "година"},
+	"forecast": {"forecast", "predicted", "prediction", "прогноз"},
+	"actual":   {"actual", "fact", "measured", "факт"},
+}
+
+// Формати позначки часу в історії прогнозів
+var historyTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+}
+
+// Запис історії: година доби (-1, якщо невідома), прогнозована та фактична потужність, МВт
+type HistoryRecord struct {
+	Hour     int
+	Forecast float64
+	Actual   float64
+}
+
+// Оцінка похибки прогнозу (факт мінус прогноз) за історією
+type ErrorFit struct {
+	Count        int     `json:"count"`
+	Bias         float64 `json:"bias"`
+	Sigma        float64 `json:"sigma"`
+	MAE          float64 `json:"mae"`
+	RMSE         float64 `json:"rmse"`
+	MeanForecast float64 `json:"meanForecast"`
+}
+
+// Розбір CSV з прогнозом і фактом: стовпці визначаються за заголовком або,
+// без нього, як [час,] прогноз, факт; підтримується роздільник ";" з десятковою комою
+func parseForecastHistory(r io.Reader) ([]HistoryRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	firstLine, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	decimalComma := strings.Contains(firstLine, ";")
+	if decimalComma {
+		reader.Comma = ';'
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Помилка читання CSV: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV-файл порожній")
+	}
+
+	parseNumber := func(value string) (float64, error) {
+		value = strings.TrimSpace(value)
+		if decimalComma {
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+		return strconv.ParseFloat(value, 64)
+	}
+
+	timeColumn, forecastColumn, actualColumn := -1, 0, 1
+	if len(rows[0]) >= 3 {
+		timeColumn, forecastColumn, actualColumn = 0, 1, 2
+	}
+	if _, err := parseNumber(rows[0][len(rows[0])-1]); err != nil {
+		columns := map[string]int{}
+		for i, name := range rows[0] {
+			name = strings.ToLower(strings.TrimSpace(name))
+			for column, aliases := range historyColumns {
+				for _, alias := range aliases {
+					if name == alias {
+						columns[column] = i
+					}
+				}
+			}
+		}
+		var ok bool
+		if forecastColumn, ok = columns["forecast"]; !ok {
+			return nil, errors.New("У заголовку CSV не знайдено стовпець прогнозу (forecast)")
+		}
+		if actualColumn, ok = columns["actual"]; !ok {
+			return nil, errors.New("У заголовку CSV не знайдено стовпець факту (actual)")
+		}
+		if timeColumn, ok = columns["time"]; !ok {
+			timeColumn = -1
+		}
+		rows = rows[1:]
+	}
+
+	records := make([]HistoryRecord, 0, len(rows))
+	for i, row := range rows {
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		if len(row) <= forecastColumn || len(row) <= actualColumn || len(row) <= timeColumn {
+			return nil, fmt.Errorf("Рядок %d: недостатньо стовпців", i+1)
+		}
+		forecast, err := parseNumber(row[forecastColumn])
+		if err != nil {
+			return nil, fmt.Errorf("Рядок %d: некоректний прогноз %q", i+1, row[forecastColumn])
+		}
+		actual, err := parseNumber(row[actualColumn])
+		if err != nil {
+			return nil, fmt.Errorf("Рядок %d: некоректний факт %q", i+1, row[actualColumn])
+		}
+
+		hour := -1
+		if timeColumn >= 0 {
+			if hour, err = parseHour(row[timeColumn]); err != nil {
+				return nil, fmt.Errorf("Рядок %d: %v", i+1, err)
+			}
+		}
+		records = append(records, HistoryRecord{Hour: hour, Forecast: forecast, Actual: actual})
+	}
+	return records, nil
+}
+
+// Година доби з позначки часу або з номера години 0..23
+func parseHour(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if hour, err := strconv.Atoi(value); err == nil {
+		if hour < 0 || hour >= hoursPerDay {
+			return 0, fmt.Errorf("година %d поза межами 0..23", hour)
+		}
+		return hour, nil
+	}
+	for _, layout := range historyTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Hour(), nil
+		}
+	}
+	return 0, fmt.Errorf("некоректна позначка часу %q", value)
+}
+
+// Систематична (зміщення) та випадкова (стандартне відхилення) складові похибки прогнозу
+func fitForecastError(records []HistoryRecord) ErrorFit {
+	fit := ErrorFit{Count: len(records)}
+	if len(records) == 0 {
+		return fit
+	}
+
+	var sumForecast, sumError, sumAbs, sumSquares float64
+	for _, record := range records {
+		deviation := record.Actual - record.Forecast
+		sumForecast += record.Forecast
+		sumError += deviation
+		sumAbs += math.Abs(deviation)
+		sumSquares += deviation * deviation
+	}
+	n := float64(len(records))
+	fit.MeanForecast = sumForecast / n
+	fit.Bias = sumError / n
+	fit.MAE = sumAbs / n
+	fit.RMSE = math.Sqrt(sumSquares / n)
+
+	if len(records) > 1 {
+		var variance float64
+		for _, record := range records {
+			deviation := record.Actual - record.Forecast - fit.Bias
+			variance += deviation * deviation
+		}
+		fit.Sigma = math.Sqrt(variance / (n - 1))
+	}
+	return fit
+}
+
+// Оцінка похибки окремо для кожної години доби; години з недостатньою кількістю
+// записів отримують загальні зміщення та стандартне відхилення
+func fitForecastErrorByHour(records []HistoryRecord) ([]ErrorFit, error) {
+	byHour := make([][]HistoryRecord, hoursPerDay)
+	for _, record := range records {
+		if record.Hour < 0 {
+			return nil, errors.New("Для погодинної оцінки CSV повинен містити стовпець часу")
+		}
+		byHour[record.Hour] = append(byHour[record.Hour], record)
+	}
+
+	overall := fitForecastError(records)
+	fits := make([]ErrorFit, hoursPerDay)
+	for hour, hourRecords := range byHour {
+		fits[hour] = fitForecastError(hourRecords)
+		if len(hourRecords) < minHourlyRecords || fits[hour].Sigma == 0 {
+			fits[hour].Sigma, fits[hour].Bias = overall.Sigma, overall.Bias
+		}
+	}
+	return fits, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Максимальна кількість підінтервалів при подвоєнні сітки до досягнення точності
+const maxIntegrationSteps = 1 << 22
+
+// Максимальна глибина поділу та кількість обчислень функції в адаптивній квадратурі Сімпсона
+const (
+	maxAdaptiveDepth       = 50
+	maxAdaptiveEvaluations = 1 << 22
+)
+
+// Найменша допустима точність: менші значення недосяжні в арифметиці float64
+const minIntegrationTolerance = 1e-14
+
+// Параметри чисельного інтегрування; Steps — кількість підінтервалів,
+// Tolerance — допустима абсолютна похибка
+type IntegrationOptions struct {
+	Steps     int
+	Tolerance float64
+}
+
+// Результат чисельного інтегрування з оцінкою похибки
+type IntegrationResult struct {
+	Method        string  `json:"method"`
+	Value         float64 `json:"value"`
+	ErrorEstimate float64 `json:"errorEstimate"`
+	Evaluations   int     `json:"evaluations"`
+	Steps         int     `json:"steps,omitempty"`
+	Converged     bool    `json:"converged"`
+}
+
+type Integrator func(f func(float64) float64, a, b float64, opts IntegrationOptions) IntegrationResult
+
+// Доступні методи інтегрування
+var integrators = map[string]Integrator{
+	"trapezoid":      fixedRule("trapezoid", trapezoidRule, 2, 10000),
+	"simpson":        fixedRule("simpson", simpsonRule, 4, 1000),
+	"gauss-legendre": fixedRule("gauss-legendre", gaussLegendreRule, 10, 20),
+	"adaptive":       adaptiveSimpson,
+}
+
+// Складена формула трапецій на n підінтервалах
+func trapezoidRule(f func(float64) float64, a, b float64, n int) float64 {
+	h := (b - a) / float64(n)
+	sum := 0.5 * (f(a) + f(b))
+	for i := 1; i < n; i++ {
+		sum += f(a + float64(i)*h)
+	}
+	return sum * h
+}
+
+// Складена формула Сімпсона на n підінтервалах (по три вузли на кожному)
+func simpsonRule(f func(float64) float64, a, b float64, n int) float64 {
+	h := (b - a) / float64(n)
+	sum := f(a) + f(b)
+	for i := 0; i < n; i++ {
+		left := a + float64(i)*h
+		sum += 4 * f(left+h/2)
+		if i > 0 {
+			sum += 2 * f(left)
+		}
+	}
+	return sum * h / 6
+}
+
+// Вузли та ваги 5-точкової квадратури Гаусса–Лежандра на [-1, 1]
+var gaussLegendreNodes = []float64{0, -0.5384693101056831, 0.5384693101056831, -0.9061798459386640, 0.9061798459386640}
+var gaussLegendreWeights = []float64{0.5688888888888889, 0.4786286704993665, 0.4786286704993665, 0.2369268850561891, 0.2369268850561891}
+
+// Складена 5-точкова квадратура Гаусса–Лежандра на n підінтервалах
+func gaussLegendreRule(f func(float64) float64, a, b float64, n int) float64 {
+	h := (b - a) / float64(n)
+	var sum float64
+	for i := 0; i < n; i++ {
+		mid := a + (float64(i)+0.5)*h
+		for j, node := range gaussLegendreNodes {
+			sum += gaussLegendreWeights[j] * f(mid+node*h/2)
+		}
+	}
+	return sum * h / 2
+}
+
+// Лічильник обчислень підінтегральної функції
+func countEvaluations(f func(float64) float64, count *int) func(float64) float64 {
+	return func(x float64) float64 {
+		*count++
+		return f(x)
+	}
+}
+
+// Метод з фіксованою сіткою: похибка оцінюється за правилом Рунге порівнянням
+// з удвічі грубішою сіткою; при заданій точності сітка подвоюється до її досягнення
+func fixedRule(name string, rule func(func(float64) float64, float64, float64, int) float64, order int, defaultSteps int) Integrator {
+	return func(f func(float64) float64, a, b float64, opts IntegrationOptions) IntegrationResult {
+		var evaluations int
+		f = countEvaluations(f, &evaluations)
+
+		n := opts.Steps
+		if n <= 0 {
+			n = defaultSteps
+		}
+		if n < 2 {
+			n = 2
+		}
+		runge := math.Pow(2, float64(order)) - 1
+
+		coarse := rule(f, a, b, n/2)
+		fine := rule(f, a, b, n)
+		errorEstimate := math.Abs(fine-coarse) / runge
+		for opts.Tolerance > 0 && errorEstimate > opts.Tolerance && 2*n <= maxIntegrationSteps {
+			n *= 2
+			coarse, fine = fine, rule(f, a, b, n)
+			errorEstimate = math.Abs(fine-coarse) / runge
+		}
+
+		return IntegrationResult{
+			Method:        name,
+			Value:         fine,
+			ErrorEstimate: errorEstimate,
+			Evaluations:   evaluations,
+			Steps:         n,
+			Converged:     opts.Tolerance <= 0 || errorEstimate <= opts.Tolerance,
+		}
+	}
+}
+
+// Адаптивна квадратура Сімпсона з локальним поділом відрізка до досягнення точності
+func adaptiveSimpson(f func(float64) float64, a, b float64, opts IntegrationOptions) IntegrationResult {
+	var evaluations int
+	f = countEvaluations(f, &evaluations)
+
+	tolerance := opts.Tolerance
+	if tolerance <= 0 {
+		tolerance = 1e-10
+	}
+
+	converged := true
+	var errorEstimate float64
+	var step func(a, b, fa, fm, fb, whole, tolerance float64, depth int) float64
+	step = func(a, b, fa, fm, fb, whole, tolerance float64, depth int) float64 {
+		m := (a + b) / 2
+		lm, rm := (a+m)/2, (m+b)/2
+		flm, frm := f(lm), f(rm)
+		left := (m - a) / 6 * (fa + 4*flm + fm)
+		right := (b - m) / 6 * (fm + 4*frm + fb)
+		delta := left + right - whole
+
+		// Поділ припиняється також при вичерпанні глибини або ліміту обчислень,
+		// тоді результат позначається як такий, що не досяг точності
+		exhausted := depth >= maxAdaptiveDepth || evaluations >= maxAdaptiveEvaluations
+		if math.Abs(delta) <= 15*tolerance || exhausted {
+			if math.Abs(delta) > 15*tolerance {
+				converged = false
+			}
+			errorEstimate += math.Abs(delta) / 15
+			return left + right + delta/15
+		}
+		return step(a, m, fa, flm, fm, left, tolerance/2, depth+1) +
+			step(m, b, fm, frm, fb, right, tolerance/2, depth+1)
+	}
+
+	fa, fm, fb := f(a), f((a+b)/2), f(b)
+	value := step(a, b, fa, fm, fb, (b-a)/6*(fa+4*fm+fb), tolerance, 0)
+
+	return IntegrationResult{
+		Method:        "adaptive",
+		Value:         value,
+		ErrorEstimate: errorEstimate,
+		Evaluations:   evaluations,
+		Converged:     converged,
+	}
+}
+
+// Інтегрування функції методом, вибраним за назвою
+func integrate(method string, f func(float64) float64, a, b float64, opts IntegrationOptions) (IntegrationResult, error) {
+	integrator, ok := integrators[method]
+	if !ok {
+		return IntegrationResult{}, fmt.Errorf("Невідомий метод інтегрування: %s", method)
+	}
+	return integrator(f, a, b, opts), nil
+}
+
+// Інтегрування по півнескінченному проміжку від a в напрямку direction (±1)
+// заміною x = a ± t/(1-t), що зводить його до відрізка [0, 1]
+func integrateTail(method string, f func(float64) float64, a, direction float64, opts IntegrationOptions) (IntegrationResult, error) {
+	g := func(t float64) float64 {
+		if t >= 1 {
+			return 0
+		}
+		return f(a+direction*t/(1-t)) / ((1 - t) * (1 - t))
+	}
+	return integrate(method, g, 0, 1, opts)
+}
+
+// Функція розподілу нормального закону через функцію помилок
+func normalCDF(x, mean, sigma float64) float64 {
+	return 0.5 * (1 + math.Erf((x-mean)/(sigma*math.Sqrt2)))
+}
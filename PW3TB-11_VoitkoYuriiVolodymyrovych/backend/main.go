@@ -1,6 +1,9 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"log"
@@ -9,10 +12,128 @@ import (
 	"time"
 )
 
+// Константи розрахунку за замовчуванням: метод інтегрування та смуга допуску
+// відхилення потужності від прогнозу без небалансів (±5%)
+const (
+	defaultIntegrationMethod = "trapezoid"
+	defaultToleranceBand     = 0.05
+	hoursPerDay              = 24
+)
+
 type CalculationRequest struct {
 	Pc    float64 `json:"Pc"`
 	Sigma float64 `json:"Sigma"`
 	B     float64 `json:"B"`
+
+	// Систематична похибка прогнозу (середнє відхилення факту від прогнозу), МВт
+	Bias float64 `json:"bias,omitempty"`
+
+	// Метод інтегрування: trapezoid, simpson, gauss-legendre, adaptive або
+	// cdf (erf для нормального розподілу) — точний розв'язок через функцію розподілу
+	Method    string  `json:"method,omitempty"`
+	Steps     int     `json:"steps,omitempty"`
+	Tolerance float64 `json:"tolerance,omitempty"`
+	Band      float64 `json:"band,omitempty"`
+
+	// Коефіцієнти штрафу до тарифу B за надлишок і дефіцит генерації поза смугою допуску
+	SurplusPenalty *float64 `json:"surplusPenalty,omitempty"`
+	DeficitPenalty *float64 `json:"deficitPenalty,omitempty"`
+
+	Distribution DistributionSpec `json:"distribution"`
+}
+
+// Порівняння базової (Sigma) та покращеної системи прогнозування
+type CalculationRequest2 struct {
+	CalculationRequest
+
+	ImprovedSigma float64 `json:"improvedSigma"`
+}
+
+// Погодинний (24) або поквартальний (96) прогноз на добу; незадані в інтервалі
+// Sigma, B та bias беруться із загальних параметрів запиту
+type CalculationRequest3 struct {
+	CalculationRequest
+
+	Intervals []ForecastInterval `json:"intervals"`
+}
+
+// Прогноз потужності, його похибка та тариф для одного інтервалу торгів
+type ForecastInterval struct {
+	Pc    float64  `json:"Pc"`
+	Sigma *float64 `json:"Sigma,omitempty"`
+	B     *float64 `json:"B,omitempty"`
+	Bias  *float64 `json:"bias,omitempty"`
+}
+
+// Параметри розрахунку для історії прогнозів з CSV; Pc за замовчуванням — середній
+// прогноз, byHour — окремі похибки та прогнози для кожної години доби
+type CalculationRequest4 struct {
+	CalculationRequest
+
+	ByHour bool `json:"byHour,omitempty"`
+}
+
+// Портфель електростанцій під одним балансуючим суб'єктом; загальні параметри
+// (B, метод, смуга, штрафи, розподіл) застосовуються до кожної станції та портфеля.
+// Параметри в МВт однієї станції (bias, розподіли beta та empirical) не підтримуються,
+// бо не переносяться ні на інші станції, ні на сумарну потужність портфеля
+type CalculationRequest5 struct {
+	CalculationRequest
+
+	Plants            []Plant     `json:"plants"`
+	Correlation       [][]float64 `json:"correlation,omitempty"`
+	CorrelationLength float64     `json:"correlationLength,omitempty"`
+}
+
+// Вітрова електростанція: прогноз швидкості вітру, м/с, та її похибка (стандартне
+// відхилення windSigma або параметр форми shape розподілу Вейбулла); прогноз потужності
+// Pc визначається за кривою потужності (точки powerCurve або ratedPower зі швидкостями);
+// Pc, Sigma, bias та distribution із загальних параметрів не задаються
+type CalculationRequest6 struct {
+	CalculationRequest
+
+	WindSpeed float64 `json:"windSpeed"`
+	WindSigma float64 `json:"windSigma,omitempty"`
+	Shape     float64 `json:"shape,omitempty"`
+
+	PowerCurve  []PowerCurvePoint `json:"powerCurve,omitempty"`
+	RatedPower  float64           `json:"ratedPower,omitempty"`
+	CutInSpeed  float64           `json:"cutInSpeed,omitempty"`
+	RatedSpeed  float64           `json:"ratedSpeed,omitempty"`
+	CutOutSpeed float64           `json:"cutOutSpeed,omitempty"`
+}
+
+// Результат для вітрової електростанції: параметри розподілу Вейбулла та межі
+// швидкості вітру, в яких генерація лишається в смузі допуску
+type WindForecastResult struct {
+	ForecastResult
+
+	Pc          float64 `json:"Pc"`
+	Shape       float64 `json:"shape"`
+	Scale       float64 `json:"scale"`
+	SpeedLow    float64 `json:"speedLow"`
+	SpeedHigh   float64 `json:"speedHigh"`
+	CutOutSpeed float64 `json:"cutOutSpeed"`
+}
+
+// Доход, штраф і прибуток за період при заданій похибці прогнозу
+type ForecastResult struct {
+	Revenue             float64           `json:"revenue"`
+	Fine                float64           `json:"fine"`
+	Profit              float64           `json:"profit"`
+	BalancedEnergyShare float64           `json:"balancedEnergyShare"`
+	Surplus             ImbalanceResult   `json:"surplus"`
+	Deficit             ImbalanceResult   `json:"deficit"`
+	Distribution        string            `json:"distribution"`
+	Integration         IntegrationResult `json:"integration"`
+}
+
+// Частка енергії в хвості розподілу поза смугою допуску та штраф за неї
+type ImbalanceResult struct {
+	Share       float64           `json:"share"`
+	Penalty     float64           `json:"penalty"`
+	Fine        float64           `json:"fine"`
+	Integration IntegrationResult `json:"integration"`
 }
 
 // Нормальний розподіл
@@ -20,23 +141,143 @@ func calculateNormalDistribution(power, averagePower, standardDeviation float64)
 	return (1 / (standardDeviation * math.Sqrt(2*math.Pi))) * math.Exp(-math.Pow(power-averagePower, 2)/(2*math.Pow(standardDeviation, 2)))
 }
 
-// Інтеграція за допомогою методу трапецій
-func integrateEnergyShare(function func(float64, float64, float64) float64, averagePower, sigma, totalSteps, deviationFactor float64) float64 {
-	lowerLimit := averagePower * (1 - deviationFactor)
-	upperLimit := averagePower * (1 + deviationFactor)
-	stepSize := (upperLimit - lowerLimit) / totalSteps
-	var result float64
+// Частка енергії між потужностями lower та upper; нескінченні межі задаються math.Inf
+func integrateShare(distribution Distribution, lower, upper float64, method string, opts IntegrationOptions) (IntegrationResult, error) {
+	// Точний розв'язок через функцію розподілу як еталон для чисельних методів
+	if method == "cdf" || method == "erf" {
+		return IntegrationResult{
+			Method:    method,
+			Value:     distribution.CDF(upper) - distribution.CDF(lower),
+			Converged: true,
+		}, nil
+	}
+
+	switch {
+	case math.IsInf(lower, -1):
+		return integrateTail(method, distribution.PDF, upper, -1, opts)
+	case math.IsInf(upper, 1):
+		return integrateTail(method, distribution.PDF, lower, 1, opts)
+	}
+	return integrate(method, distribution.PDF, lower, upper, opts)
+}
+
+// Частка енергії в смузі допуску ±deviationFactor навколо прогнозу
+func integrateEnergyShare(distribution Distribution, averagePower, deviationFactor float64, method string, opts IntegrationOptions) (IntegrationResult, error) {
+	return integrateShare(distribution, averagePower*(1-deviationFactor), averagePower*(1+deviationFactor), method, opts)
+}
+
+// Частки енергії дефіциту (нижче смуги допуску) та надлишку (вище неї)
+func integrateImbalance(distribution Distribution, averagePower, deviationFactor float64, method string, opts IntegrationOptions) (IntegrationResult, IntegrationResult, error) {
+	deficit, err := integrateShare(distribution, math.Inf(-1), averagePower*(1-deviationFactor), method, opts)
+	if err != nil {
+		return IntegrationResult{}, IntegrationResult{}, err
+	}
+	surplus, err := integrateShare(distribution, averagePower*(1+deviationFactor), math.Inf(1), method, opts)
+	if err != nil {
+		return IntegrationResult{}, IntegrationResult{}, err
+	}
+	return deficit, surplus, nil
+}
+
+// Перевірка параметрів запиту та заповнення значень за замовчуванням
+func (req *CalculationRequest) validate() error {
+	if req.Pc <= 0 {
+		return errors.New("Прогнозована потужність повинна бути додатною")
+	}
+	if req.Sigma < 0 {
+		return errors.New("Похибка прогнозу не може бути від'ємною")
+	}
+	if req.Method == "" {
+		req.Method = defaultIntegrationMethod
+	}
+	if req.Band == 0 {
+		req.Band = defaultToleranceBand
+	}
+	if req.Band < 0 || req.Band >= 1 {
+		return errors.New("Смуга допуску повинна бути в межах від 0 до 1")
+	}
+	if req.Steps < 0 || req.Tolerance < 0 {
+		return errors.New("Кількість кроків і точність не можуть бути від'ємними")
+	}
+	if req.Steps > maxIntegrationSteps {
+		return fmt.Errorf("Кількість кроків не може перевищувати %d", maxIntegrationSteps)
+	}
+	if req.Tolerance > 0 && req.Tolerance < minIntegrationTolerance {
+		return fmt.Errorf("Точність не може бути меншою за %g", minIntegrationTolerance)
+	}
+
+	// За замовчуванням небаланси в обидва боки штрафуються за тарифом B
+	one := 1.0
+	if req.SurplusPenalty == nil {
+		req.SurplusPenalty = &one
+	}
+	if req.DeficitPenalty == nil {
+		req.DeficitPenalty = &one
+	}
+	if *req.SurplusPenalty < 0 || *req.DeficitPenalty < 0 {
+		return errors.New("Коефіцієнти штрафу не можуть бути від'ємними")
+	}
+	return nil
+}
+
+// Розрахунок доходу, штрафу та прибутку для перевіреного запиту за період hours, год
+func evaluateForecast(req CalculationRequest, hours float64) (ForecastResult, error) {
+	distribution, err := req.Distribution.build(req.Pc+req.Bias, req.Sigma)
+	if err != nil {
+		return ForecastResult{}, err
+	}
 
-	totalStepsInt := int(totalSteps)
+	// Частка енергії, що генерується без небалансів
+	integration, err := integrateEnergyShare(distribution, req.Pc, req.Band, req.Method, IntegrationOptions{
+		Steps:     req.Steps,
+		Tolerance: req.Tolerance,
+	})
+	if err != nil {
+		return ForecastResult{}, err
+	}
 
-	// Виконання інтеграції
-	for i := 0; i < totalStepsInt; i++ {
-		currentPoint := lowerLimit + float64(i)*stepSize
-		nextPoint := currentPoint + stepSize
-		result += 0.5 * (function(currentPoint, averagePower, sigma) + function(nextPoint, averagePower, sigma)) * stepSize
+	// Частки енергії в кожному з хвостів розподілу
+	deficitIntegration, surplusIntegration, err := integrateImbalance(distribution, req.Pc, req.Band, req.Method, IntegrationOptions{
+		Steps:     req.Steps,
+		Tolerance: req.Tolerance,
+	})
+	if err != nil {
+		return ForecastResult{}, err
 	}
+	return settleForecast(req, hours, req.Distribution.name(), integration, deficitIntegration, surplusIntegration), nil
+}
 
-	return result
+// Доход, штрафи за дефіцит і надлишок та прибуток за отриманими частками енергії
+func settleForecast(req CalculationRequest, hours float64, distribution string, integration, deficitIntegration, surplusIntegration IntegrationResult) ForecastResult {
+	balancedEnergyShare := integration.Value
+	surplus := ImbalanceResult{
+		Share:       surplusIntegration.Value,
+		Penalty:     *req.SurplusPenalty,
+		Fine:        req.Pc * hours * surplusIntegration.Value * req.B * *req.SurplusPenalty,
+		Integration: surplusIntegration,
+	}
+	deficit := ImbalanceResult{
+		Share:       deficitIntegration.Value,
+		Penalty:     *req.DeficitPenalty,
+		Fine:        req.Pc * hours * deficitIntegration.Value * req.B * *req.DeficitPenalty,
+		Integration: deficitIntegration,
+	}
+
+	// Обчислення доходу, штрафу та прибутку
+	revenue := req.Pc * hours * balancedEnergyShare * req.B
+	fine := surplus.Fine + deficit.Fine
+	profit := revenue - fine
+
+	return ForecastResult{
+		Revenue:             revenue,
+		Fine:                fine,
+		Profit:              profit,
+		BalancedEnergyShare: balancedEnergyShare,
+		Surplus:             surplus,
+		Deficit:             deficit,
+		Distribution:        distribution,
+		Integration:         integration,
+	}
 }
 
 func calculate(c *gin.Context) {
@@ -46,18 +287,430 @@ func calculate(c *gin.Context) {
 		return
 	}
 
-	// Частка енергії, що генерується без небалансів
-	balancedEnergyShare := integrateEnergyShare(calculateNormalDistribution, req.Pc, req.Sigma, 10000, 0.05)
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Обчислення доходу, штрафу та прибутку
-	revenue := req.Pc * 24 * balancedEnergyShare * req.B
-	fine := req.Pc * 24 * (1 - balancedEnergyShare) * req.B
-	profit := revenue - fine
+	result, err := evaluateForecast(req, hoursPerDay)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Цінність покращення прогнозу: результати для обох похибок та їх різниця
+func calculate2(c *gin.Context) {
+	var req CalculationRequest2
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ImprovedSigma <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Похибка покращеного прогнозу повинна бути додатною"})
+		return
+	}
+
+	baseline, err := evaluateForecast(req.CalculationRequest, hoursPerDay)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	improvedReq := req.CalculationRequest
+	improvedReq.Sigma = req.ImprovedSigma
+	improved, err := evaluateForecast(improvedReq, hoursPerDay)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
+		"baseline": baseline,
+		"improved": improved,
+		"delta": gin.H{
+			"revenue":             improved.Revenue - baseline.Revenue,
+			"fine":                improved.Fine - baseline.Fine,
+			"profit":              improved.Profit - baseline.Profit,
+			"balancedEnergyShare": improved.BalancedEnergyShare - baseline.BalancedEnergyShare,
+		},
+	})
+}
+
+// Частка збалансованої енергії, доход і штраф за кожен інтервал торгів та за добу
+func settleIntervals(req CalculationRequest3) (gin.H, error) {
+	if len(req.Intervals) != 24 && len(req.Intervals) != 96 {
+		return nil, errors.New("Потрібно задати 24 погодинні або 96 поквартальних інтервалів")
+	}
+	intervalHours := float64(hoursPerDay) / float64(len(req.Intervals))
+
+	var energy, balancedEnergy, revenue, fine float64
+	intervals := make([]gin.H, len(req.Intervals))
+	for i, interval := range req.Intervals {
+		minutes := int(float64(i) * intervalHours * 60)
+		start := fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+
+		// При нульовому прогнозі (ніч) енергії та небалансів немає
+		if interval.Pc == 0 {
+			intervals[i] = gin.H{"interval": i, "start": start, "energy": 0, "revenue": 0, "fine": 0, "profit": 0}
+			continue
+		}
+
+		intervalReq := req.CalculationRequest
+		intervalReq.Pc = interval.Pc
+		if interval.Sigma != nil {
+			intervalReq.Sigma = *interval.Sigma
+		}
+		if interval.B != nil {
+			intervalReq.B = *interval.B
+		}
+		if interval.Bias != nil {
+			intervalReq.Bias = *interval.Bias
+		}
+		if err := intervalReq.validate(); err != nil {
+			return nil, fmt.Errorf("Інтервал %s: %v", start, err)
+		}
+		result, err := evaluateForecast(intervalReq, intervalHours)
+		if err != nil {
+			return nil, fmt.Errorf("Інтервал %s: %v", start, err)
+		}
+
+		intervalEnergy := intervalReq.Pc * intervalHours
+		energy += intervalEnergy
+		balancedEnergy += intervalEnergy * result.BalancedEnergyShare
+		revenue += result.Revenue
+		fine += result.Fine
+
+		intervals[i] = gin.H{
+			"interval":            i,
+			"start":               start,
+			"Pc":                  intervalReq.Pc,
+			"Sigma":               intervalReq.Sigma,
+			"B":                   intervalReq.B,
+			"energy":              intervalEnergy,
+			"balancedEnergyShare": result.BalancedEnergyShare,
+			"revenue":             result.Revenue,
+			"fine":                result.Fine,
+			"surplusFine":         result.Surplus.Fine,
+			"deficitFine":         result.Deficit.Fine,
+			"profit":              result.Profit,
+			"errorEstimate":       result.Integration.ErrorEstimate,
+		}
+	}
+
+	daily := gin.H{
+		"energy":  energy,
 		"revenue": revenue,
 		"fine":    fine,
-		"profit":  profit,
+		"profit":  revenue - fine,
+	}
+	if energy > 0 {
+		daily["balancedEnergyShare"] = balancedEnergy / energy
+	}
+
+	return gin.H{
+		"intervalHours": intervalHours,
+		"intervals":     intervals,
+		"daily":         daily,
+	}, nil
+}
+
+func calculate3(c *gin.Context) {
+	var req CalculationRequest3
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	response, err := settleIntervals(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Підбір похибки прогнозу за історією з CSV (поле file) та розрахунок доходу;
+// параметри розрахунку передаються JSON-рядком у полі request
+func calculate4(c *gin.Context) {
+	var req CalculationRequest4
+	if params := c.PostForm("request"); params != "" {
+		if err := json.Unmarshal([]byte(params), &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Потрібно завантажити CSV-файл у полі file"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	records, err := parseForecastHistory(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fit := fitForecastError(records)
+	if fit.Count < 2 || fit.Sigma == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Недостатньо даних для оцінки похибки прогнозу"})
+		return
+	}
+
+	req.Sigma, req.Bias = fit.Sigma, fit.Bias
+	if req.Pc == 0 {
+		req.Pc = fit.MeanForecast
+	}
+
+	if !req.ByHour {
+		if err := req.validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result, err := evaluateForecast(req.CalculationRequest, hoursPerDay)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"fit": fit, "result": result})
+		return
+	}
+
+	hourly, err := fitForecastErrorByHour(records)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Години з недостатньою кількістю даних використовують загальну похибку
+	intervals := make([]ForecastInterval, hoursPerDay)
+	for hour, hourFit := range hourly {
+		sigma, bias := hourFit.Sigma, hourFit.Bias
+		intervals[hour] = ForecastInterval{Pc: hourFit.MeanForecast, Sigma: &sigma, Bias: &bias}
+	}
+	settlement, err := settleIntervals(CalculationRequest3{CalculationRequest: req.CalculationRequest, Intervals: intervals})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"fit": fit, "hourlyFit": hourly, "result": settlement})
+}
+
+// Частка збалансованої енергії та прибуток портфеля порівняно з окремими станціями
+func calculate5(c *gin.Context) {
+	var req CalculationRequest5
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if len(req.Plants) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Портфель повинен містити щонайменше дві станції"})
+		return
+	}
+	if req.Bias != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Зміщення прогнозу bias задається для однієї станції і не підтримується для портфеля"})
+		return
+	}
+	switch req.Distribution.name() {
+	case "beta", "empirical":
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Розподіл %s задається в МВт для однієї станції і не підтримується для портфеля", req.Distribution.name())})
+		return
+	}
+	correlation, err := correlationMatrix(req.Plants, req.Correlation, req.CorrelationLength)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var energy, balancedEnergy, revenue, fine, sigmaSum float64
+	plants := make([]gin.H, len(req.Plants))
+	for i, plant := range req.Plants {
+		plantReq := req.CalculationRequest
+		plantReq.Pc, plantReq.Sigma = plant.Pc, plant.Sigma
+		if err := plantReq.validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Станція %s: %v", plant.Name, err)})
+			return
+		}
+		result, err := evaluateForecast(plantReq, hoursPerDay)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Станція %s: %v", plant.Name, err)})
+			return
+		}
+
+		energy += plant.Pc * hoursPerDay
+		balancedEnergy += plant.Pc * hoursPerDay * result.BalancedEnergyShare
+		revenue += result.Revenue
+		fine += result.Fine
+		sigmaSum += plant.Sigma
+
+		plants[i] = gin.H{
+			"name":       plant.Name,
+			"location":   plant.Location,
+			"Pc":         plant.Pc,
+			"Sigma":      plant.Sigma,
+			"standalone": result,
+		}
+	}
+
+	// Сумарний прогноз портфеля та похибка з урахуванням кореляції
+	portfolioReq := req.CalculationRequest
+	portfolioReq.Pc = energy / hoursPerDay
+	portfolioReq.Sigma = portfolioSigma(req.Plants, correlation)
+	if err := portfolioReq.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	portfolio, err := evaluateForecast(portfolioReq, hoursPerDay)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	standaloneShare := balancedEnergy / energy
+	c.JSON(http.StatusOK, gin.H{
+		"plants": plants,
+		"standalone": gin.H{
+			"revenue":             revenue,
+			"fine":                fine,
+			"profit":              revenue - fine,
+			"balancedEnergyShare": standaloneShare,
+		},
+		"portfolio": gin.H{
+			"Pc":     portfolioReq.Pc,
+			"Sigma":  portfolioReq.Sigma,
+			"result": portfolio,
+		},
+		"correlation": correlation,
+		// Відношення похибки портфеля до суми похибок станцій (1 — без виграшу)
+		"diversificationRatio": portfolioReq.Sigma / sigmaSum,
+		"benefit": gin.H{
+			"revenue":             portfolio.Revenue - revenue,
+			"fine":                portfolio.Fine - fine,
+			"profit":              portfolio.Profit - (revenue - fine),
+			"balancedEnergyShare": portfolio.BalancedEnergyShare - standaloneShare,
+		},
+	})
+}
+
+// Доход, штраф і прибуток вітрової електростанції: частки енергії інтегруються
+// за розподілом Вейбулла швидкості вітру між межами, отриманими з кривої потужності
+func calculate6(c *gin.Context) {
+	var req CalculationRequest6
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var curve PowerCurve
+	var err error
+	if len(req.PowerCurve) > 0 {
+		curve, err = pointsPowerCurve(req.PowerCurve)
+	} else {
+		cutIn, rated, cutOut := defaultCutInSpeed, defaultRatedSpeed, defaultCutOutSpeed
+		if req.CutInSpeed != 0 {
+			cutIn = req.CutInSpeed
+		}
+		if req.RatedSpeed != 0 {
+			rated = req.RatedSpeed
+		}
+		if req.CutOutSpeed != 0 {
+			cutOut = req.CutOutSpeed
+		}
+		curve, err = parametricPowerCurve(req.RatedPower, cutIn, rated, cutOut)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.WindSpeed <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Прогноз швидкості вітру повинен бути додатним"})
+		return
+	}
+	// Потужність розподілена за Вейбуллом швидкості вітру, тому параметри похибки
+	// потужності не застосовуються
+	if req.Pc != 0 || req.Sigma != 0 || req.Bias != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pc, Sigma та bias не задаються для вітрової станції: потужність визначається кривою потужності за швидкістю вітру"})
+		return
+	}
+	if req.Distribution.Type != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Для вітрової станції використовується розподіл Вейбулла, distribution не задається"})
+		return
+	}
+	if req.WindSigma != 0 && req.Shape != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Потрібно задати або windSigma, або shape, але не обидва"})
+		return
+	}
+	if req.WindSigma < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Похибка прогнозу швидкості вітру не може бути від'ємною"})
+		return
+	}
+	shape := req.Shape
+	switch {
+	case req.WindSigma > 0:
+		if shape, err = fitWeibullShape(req.WindSigma / req.WindSpeed); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case shape == 0:
+		shape = defaultWeibullK
+	case shape < 1:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр форми розподілу Вейбулла повинен бути не меншим за 1"})
+		return
+	}
+	// Масштаб підбирається так, щоб середня швидкість дорівнювала прогнозу
+	weibull := WeibullDistribution{Shape: shape, Scale: req.WindSpeed / math.Gamma(1+1/shape)}
+
+	req.Pc, req.Sigma = curve.Power(req.WindSpeed), 0
+	if req.Pc <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Прогноз швидкості вітру поза робочим діапазоном вітроустановки"})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	speedLow := curve.speedFor(req.Pc*(1-req.Band), true)
+	speedHigh := curve.speedFor(req.Pc*(1+req.Band), false)
+	opts := IntegrationOptions{Steps: req.Steps, Tolerance: req.Tolerance}
+
+	shares := make([]IntegrationResult, 4)
+	for i, limits := range [][2]float64{
+		{speedLow, speedHigh},       // у смузі допуску
+		{0, speedLow},               // дефіцит: слабкий вітер
+		{curve.cutOut, math.Inf(1)}, // дефіцит: відключення при сильному вітрі
+		{speedHigh, curve.cutOut},   // надлишок
+	} {
+		if shares[i], err = integrateShare(weibull, limits[0], limits[1], req.Method, opts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result := settleForecast(req.CalculationRequest, hoursPerDay, "weibull", shares[0], addIntegrations(shares[1], shares[2]), shares[3])
+	c.JSON(http.StatusOK, WindForecastResult{
+		ForecastResult: result,
+		Pc:             req.Pc,
+		Shape:          weibull.Shape,
+		Scale:          weibull.Scale,
+		SpeedLow:       speedLow,
+		SpeedHigh:      speedHigh,
+		CutOutSpeed:    curve.cutOut,
 	})
 }
 
@@ -73,6 +726,11 @@ func main() {
 	}))
 
 	r.POST("/api/calculate1", calculate)
+	r.POST("/api/calculate2", calculate2)
+	r.POST("/api/calculate3", calculate3)
+	r.POST("/api/calculate4", calculate4)
+	r.POST("/api/calculate5", calculate5)
+	r.POST("/api/calculate6", calculate6)
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("Server startup error: %v", err)
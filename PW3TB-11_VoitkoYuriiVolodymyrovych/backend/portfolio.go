@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Середній радіус Землі, км
+const earthRadius = 6371.0
+
+// Допустима похибка перевірки матриці кореляції на симетричність і додатну напіввизначеність
+const correlationTolerance = 1e-9
+
+// Географічне розташування електростанції
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Електростанція портфеля: прогноз потужності та похибка прогнозу, МВт
+type Plant struct {
+	Name     string    `json:"name"`
+	Pc       float64   `json:"Pc"`
+	Sigma    float64   `json:"Sigma"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// Відстань між двома точками за формулою гаверсинусів, км
+func distance(a, b Location) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// Матриця кореляції похибок прогнозу: задана явно або, якщо її немає,
+// оцінена за відстанню між станціями як exp(-d / correlationLength)
+func correlationMatrix(plants []Plant, correlation [][]float64, correlationLength float64) ([][]float64, error) {
+	n := len(plants)
+	if correlation == nil {
+		if correlationLength <= 0 {
+			return nil, errors.New("Потрібно задати матрицю кореляції або correlationLength, км")
+		}
+		for _, plant := range plants {
+			if plant.Location == nil {
+				return nil, fmt.Errorf("Для оцінки кореляції за відстанню станція %s повинна мати location", plant.Name)
+			}
+		}
+		correlation = make([][]float64, n)
+		for i := range correlation {
+			correlation[i] = make([]float64, n)
+			for j := range correlation[i] {
+				correlation[i][j] = math.Exp(-distance(*plants[i].Location, *plants[j].Location) / correlationLength)
+			}
+		}
+	}
+
+	if len(correlation) != n {
+		return nil, fmt.Errorf("Матриця кореляції повинна мати розмір %d×%d", n, n)
+	}
+	for i, row := range correlation {
+		if len(row) != n {
+			return nil, fmt.Errorf("Матриця кореляції повинна мати розмір %d×%d", n, n)
+		}
+		if math.Abs(row[i]-1) > correlationTolerance {
+			return nil, errors.New("Діагональні елементи матриці кореляції повинні дорівнювати 1")
+		}
+		for j, value := range row {
+			if value < -1 || value > 1 {
+				return nil, errors.New("Коефіцієнти кореляції повинні бути в межах від -1 до 1")
+			}
+			if math.Abs(value-correlation[j][i]) > correlationTolerance {
+				return nil, errors.New("Матриця кореляції повинна бути симетричною")
+			}
+		}
+	}
+	if !positiveSemidefinite(correlation) {
+		return nil, errors.New("Матриця кореляції повинна бути додатно напіввизначеною")
+	}
+	return correlation, nil
+}
+
+// Перевірка додатної напіввизначеності розкладом Холецького
+func positiveSemidefinite(matrix [][]float64) bool {
+	n := len(matrix)
+	lower := make([][]float64, n)
+	for i := range lower {
+		lower[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			sum := matrix[i][j]
+			for k := 0; k < j; k++ {
+				sum -= lower[i][k] * lower[j][k]
+			}
+			if i == j {
+				if sum < -correlationTolerance {
+					return false
+				}
+				lower[i][i] = math.Sqrt(math.Max(sum, 0))
+			} else if lower[j][j] > correlationTolerance {
+				lower[i][j] = sum / lower[j][j]
+			} else if math.Abs(sum) > correlationTolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Стандартне відхилення сумарної похибки прогнозу портфеля: sqrt(Σ ρij σi σj)
+func portfolioSigma(plants []Plant, correlation [][]float64) float64 {
+	var variance float64
+	for i := range plants {
+		for j := range plants {
+			variance += correlation[i][j] * plants[i].Sigma * plants[j].Sigma
+		}
+	}
+	return math.Sqrt(math.Max(variance, 0))
+}
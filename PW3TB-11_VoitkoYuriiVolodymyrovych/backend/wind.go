@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"math"
+)
+
+// Типові швидкості вітру вітроустановки, м/с, якщо криву потужності не задано точками
+const (
+	defaultCutInSpeed  = 3.0
+	defaultRatedSpeed  = 12.0
+	defaultCutOutSpeed = 25.0
+	defaultWeibullK    = 2.0
+)
+
+// Точка кривої потужності: швидкість вітру, м/с, та потужність, МВт
+type PowerCurvePoint struct {
+	Speed float64 `json:"speed"`
+	Power float64 `json:"power"`
+}
+
+// Крива потужності, що не спадає до швидкості відключення cutOut і дорівнює нулю після неї
+type PowerCurve struct {
+	power    func(speed float64) float64
+	cutOut   float64
+	maxPower float64
+}
+
+func (curve PowerCurve) Power(speed float64) float64 {
+	if speed < 0 || speed >= curve.cutOut {
+		return 0
+	}
+	return curve.power(speed)
+}
+
+// Крива потужності за точками з лінійною інтерполяцією; остання точка — швидкість відключення
+func pointsPowerCurve(points []PowerCurvePoint) (PowerCurve, error) {
+	if len(points) < 2 {
+		return PowerCurve{}, errors.New("Крива потужності повинна містити щонайменше дві точки")
+	}
+	for i, point := range points {
+		if point.Power < 0 {
+			return PowerCurve{}, errors.New("Потужність на кривій не може бути від'ємною")
+		}
+		if i > 0 && (point.Speed <= points[i-1].Speed || point.Power < points[i-1].Power) {
+			return PowerCurve{}, errors.New("Швидкості кривої потужності повинні зростати, а потужність не спадати")
+		}
+	}
+
+	last := points[len(points)-1]
+	return PowerCurve{
+		power: func(speed float64) float64 {
+			if speed < points[0].Speed {
+				return 0
+			}
+			for i := 1; i < len(points); i++ {
+				if speed < points[i].Speed {
+					left, right := points[i-1], points[i]
+					return left.Power + (speed-left.Speed)/(right.Speed-left.Speed)*(right.Power-left.Power)
+				}
+			}
+			return last.Power
+		},
+		cutOut:   last.Speed,
+		maxPower: last.Power,
+	}, nil
+}
+
+// Типова крива потужності: кубічне зростання від швидкості ввімкнення до номінальної
+func parametricPowerCurve(ratedPower, cutIn, rated, cutOut float64) (PowerCurve, error) {
+	if ratedPower <= 0 {
+		return PowerCurve{}, errors.New("Потрібно задати криву потужності або номінальну потужність ratedPower")
+	}
+	if cutIn < 0 || cutIn >= rated || rated >= cutOut {
+		return PowerCurve{}, errors.New("Швидкості вітру повинні задовольняти 0 ≤ cutIn < rated < cutOut")
+	}
+	return PowerCurve{
+		power: func(speed float64) float64 {
+			switch {
+			case speed < cutIn:
+				return 0
+			case speed < rated:
+				return ratedPower * (math.Pow(speed, 3) - math.Pow(cutIn, 3)) / (math.Pow(rated, 3) - math.Pow(cutIn, 3))
+			}
+			return ratedPower
+		},
+		cutOut:   cutOut,
+		maxPower: ratedPower,
+	}, nil
+}
+
+// Найменша швидкість вітру до відключення, за якої потужність перевищує power
+// (або досягає її при inclusive), знайдена методом бісекції
+func (curve PowerCurve) speedFor(power float64, inclusive bool) float64 {
+	above := func(speed float64) bool {
+		if inclusive {
+			return curve.Power(speed) >= power
+		}
+		return curve.Power(speed) > power
+	}
+	if power > curve.maxPower || (!inclusive && power >= curve.maxPower) {
+		return curve.cutOut
+	}
+
+	low, high := 0.0, curve.cutOut
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if above(mid) {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high
+}
+
+// Розподіл Вейбулла фактичної швидкості вітру
+type WeibullDistribution struct {
+	Shape, Scale float64
+}
+
+func (d WeibullDistribution) PDF(speed float64) float64 {
+	if speed < 0 {
+		return 0
+	}
+	x := speed / d.Scale
+	return d.Shape / d.Scale * math.Pow(x, d.Shape-1) * math.Exp(-math.Pow(x, d.Shape))
+}
+
+func (d WeibullDistribution) CDF(speed float64) float64 {
+	if speed <= 0 {
+		return 0
+	}
+	return 1 - math.Exp(-math.Pow(speed/d.Scale, d.Shape))
+}
+
+// Коефіцієнт варіації розподілу Вейбулла з параметром форми k
+func weibullVariation(k float64) float64 {
+	g1 := math.Gamma(1 + 1/k)
+	return math.Sqrt(math.Gamma(1+2/k)/(g1*g1) - 1)
+}
+
+// Параметр форми k ≥ 1 за коефіцієнтом варіації (спадна функція k), методом бісекції
+func fitWeibullShape(variation float64) (float64, error) {
+	low, high := 1.0, 50.0
+	if variation >= weibullVariation(low) || variation <= weibullVariation(high) {
+		return 0, errors.New("Відносна похибка прогнозу швидкості вітру повинна бути в межах розподілу Вейбулла з k від 1 до 50")
+	}
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if weibullVariation(mid) > variation {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, nil
+}
+
+// Сума результатів інтегрування по кількох відрізках
+func addIntegrations(results ...IntegrationResult) IntegrationResult {
+	total := IntegrationResult{Method: results[0].Method, Converged: true}
+	for _, result := range results {
+		total.Value += result.Value
+		total.ErrorEstimate += result.ErrorEstimate
+		total.Evaluations += result.Evaluations
+		total.Converged = total.Converged && result.Converged
+	}
+	return total
+}
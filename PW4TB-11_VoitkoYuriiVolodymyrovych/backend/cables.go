@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// Стандартний ряд перерізів жил кабелів, мм²
+var standardSections = []float64{16, 25, 35, 50, 70, 95, 120, 150, 185, 240, 300, 400, 500, 630, 800}
+
+// Тривало допустимий струм кабелю з жилами певного перерізу, А
+type CableSection struct {
+	Section  float64 `json:"section"`
+	Ampacity float64 `json:"ampacity"`
+}
+
+// Марка кабелю: тип провідника і матеріал (як у таблицях jek), номінальна напруга, кВ,
+// та допустимі струми для наявних перерізів
+type Cable struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Type     string         `json:"type"`
+	Material string         `json:"material"`
+	Voltage  float64        `json:"voltage"`
+	Sections []CableSection `json:"sections"`
+}
+
+// Початкові марки кабелів 10 кВ з паперовою ізоляцією, прокладені в землі (ПУЕ, табл. 1.3.13, 1.3.16)
+var seedCables = []Cable{
+	{
+		ID: "aabl-10", Name: "ААБл-10", Type: "paper-rubber-cable", Material: "aluminum", Voltage: 10,
+		Sections: []CableSection{
+			{16, 75}, {25, 90}, {35, 115}, {50, 140}, {70, 165},
+			{95, 205}, {120, 240}, {150, 275}, {185, 310}, {240, 355},
+		},
+	},
+	{
+		ID: "sbl-10", Name: "СБл-10", Type: "paper-rubber-cable", Material: "copper", Voltage: 10,
+		Sections: []CableSection{
+			{16, 95}, {25, 120}, {35, 150}, {50, 180}, {70, 215},
+			{95, 265}, {120, 310}, {150, 355}, {185, 400}, {240, 460},
+		},
+	},
+}
+
+var cableCatalogue *Catalogue[Cable]
+
+// Критерій вибору перерізу: потрібне значення (мм² або А для допустимого струму),
+// найменший переріз, що його задовольняє (0 — жоден), та стан перевірки:
+// met, failed або unverified (немає даних для перевірки)
+type SectionCriterion struct {
+	Criterion string  `json:"criterion"`
+	Required  float64 `json:"required"`
+	Section   float64 `json:"section"`
+	Status    string  `json:"status"`
+}
+
+// Вибраний переріз, кабель і критерій, що його визначає
+type CableSelection struct {
+	Section     float64            `json:"section"`
+	Cable       *CableRef          `json:"cable,omitempty"`
+	Ampacity    float64            `json:"ampacity,omitempty"`
+	Governing   []string           `json:"governing"`
+	Criteria    []SectionCriterion `json:"criteria"`
+	Explanation string             `json:"explanation"`
+}
+
+type CableRef struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// Назви критеріїв для пояснення вибору
+var criterionNames = map[string]string{
+	"economic": "економічною густиною струму",
+	"thermal":  "термічною стійкістю до струму КЗ",
+	"ampacity": "допустимим струмом у післяаварійному режимі",
+}
+
+func validateCable(cable Cable) error {
+	if cable.ID == "" || cable.Name == "" {
+		return errors.New("Кабель повинен мати id та name")
+	}
+	if cable.Type == "" || cable.Material == "" {
+		return errors.New("Для кабелю потрібно задати type та material")
+	}
+	if cable.Voltage <= 0 {
+		return errors.New("Номінальна напруга кабелю повинна бути додатною")
+	}
+	if len(cable.Sections) == 0 {
+		return errors.New("Кабель повинен мати хоча б один переріз")
+	}
+	for i, section := range cable.Sections {
+		if section.Section <= 0 || section.Ampacity <= 0 {
+			return errors.New("Переріз і допустимий струм повинні бути додатними")
+		}
+		if i > 0 && section.Section <= cable.Sections[i-1].Section {
+			return errors.New("Перерізи кабелю повинні йти за зростанням")
+		}
+	}
+	return nil
+}
+
+// Кабель для розрахунку: заданий за id (його тип провідника і матеріал повинні збігатися
+// з тими, для яких визначено jek) або перша марка каталогу з потрібними типом провідника,
+// матеріалом і напругою; nil, якщо такої марки немає
+func findCable(cableID string, version int, conductorType, conductorMaterial string, Unom float64) (*Revision[Cable], error) {
+	if cableID != "" {
+		cable, ok := cableCatalogue.Get(cableID, version)
+		if !ok {
+			return nil, errors.New("Кабель не знайдено: " + cableID)
+		}
+		if cable.Data.Type != conductorType || cable.Data.Material != conductorMaterial {
+			return nil, fmt.Errorf("Кабель %s (%s, %s) не відповідає провіднику, для якого визначено jek (%s, %s)",
+				cable.Data.Name, cable.Data.Type, cable.Data.Material, conductorType, conductorMaterial)
+		}
+		if cable.Data.Voltage < Unom {
+			return nil, fmt.Errorf("Кабель %s розрахований на напругу %g кВ, меншу за Unom", cable.Data.Name, cable.Data.Voltage)
+		}
+		return &cable, nil
+	}
+	for _, cable := range cableCatalogue.List() {
+		if cable.Data.Type == conductorType && cable.Data.Material == conductorMaterial && cable.Data.Voltage >= Unom {
+			return &cable, nil
+		}
+	}
+	return nil, nil
+}
+
+// Найменший переріз, що одночасно задовольняє економічну густину струму (Sek),
+// термічну стійкість (Smin) та допустимий струм не менший за Im_pa; без марки кабелю
+// з допустимими струмами переріз не вибирається, бо умову за Im_pa не перевірено
+func selectSection(cable *Revision[Cable], Sek, Smin, Im_pa float64) CableSelection {
+	sections := make([]CableSection, 0, len(standardSections))
+	if cable != nil {
+		sections = cable.Data.Sections
+	} else {
+		for _, section := range standardSections {
+			sections = append(sections, CableSection{Section: section})
+		}
+	}
+
+	criteria := []SectionCriterion{
+		{Criterion: "economic", Required: Sek},
+		{Criterion: "thermal", Required: Smin},
+		{Criterion: "ampacity", Required: Im_pa},
+	}
+	for i, criterion := range criteria {
+		if criterion.Criterion == "ampacity" && cable == nil {
+			criteria[i].Status = "unverified"
+			continue
+		}
+		criteria[i].Status = "failed"
+		for _, section := range sections {
+			value := section.Section
+			if criterion.Criterion == "ampacity" {
+				value = section.Ampacity
+			}
+			if value >= criterion.Required {
+				criteria[i].Section = section.Section
+				criteria[i].Status = "met"
+				break
+			}
+		}
+	}
+
+	selection := CableSelection{Criteria: criteria}
+	if cable != nil {
+		selection.Cable = &CableRef{ID: cable.Data.ID, Name: cable.Data.Name, Version: cable.Version}
+	}
+
+	var failed []string
+	for _, criterion := range criteria {
+		if criterion.Status == "failed" {
+			failed = append(failed, criterion.Criterion)
+		} else if criterion.Section > selection.Section {
+			selection.Section = criterion.Section
+		}
+	}
+	if len(failed) > 0 {
+		selection.Section = 0
+		selection.Governing = failed
+		reasons := make([]string, len(failed))
+		for i, criterion := range failed {
+			reasons[i] = criterionNames[criterion]
+		}
+		selection.Explanation = "Жоден доступний переріз не задовольняє умову за " + strings.Join(reasons, " та ")
+		return selection
+	}
+	if cable == nil {
+		selection.Explanation = fmt.Sprintf("Переріз не вибрано: за економічною густиною струму та термічною стійкістю потрібно щонайменше %g мм², "+
+			"але марки кабелю з допустимими струмами для цього провідника і напруги в каталозі немає, тому умову за Im_pa не перевірено", selection.Section)
+		selection.Section = 0
+		selection.Governing = []string{"ampacity"}
+		return selection
+	}
+
+	var reasons []string
+	for _, criterion := range criteria {
+		if criterion.Section == selection.Section {
+			selection.Governing = append(selection.Governing, criterion.Criterion)
+			reasons = append(reasons, criterionNames[criterion.Criterion])
+		}
+	}
+	for _, section := range sections {
+		if section.Section == selection.Section {
+			selection.Ampacity = section.Ampacity
+		}
+	}
+	selection.Explanation = fmt.Sprintf("Переріз %g мм² визначається %s", selection.Section, strings.Join(reasons, " та "))
+	return selection
+}
+
+func listCables(c *gin.Context) {
+	c.JSON(http.StatusOK, cableCatalogue.List())
+}
+
+func getCable(c *gin.Context) {
+	version, err := requestVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cable, ok := cableCatalogue.Get(c.Param("id"), version)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Кабель не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, cable)
+}
+
+func getCableHistory(c *gin.Context) {
+	history, ok := cableCatalogue.History(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Кабель не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+func createCable(c *gin.Context) {
+	var cable Cable
+	if err := c.ShouldBindJSON(&cable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateCable(cable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, created, err := cableCatalogue.Create(cable)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Кабель з таким id вже існує"})
+		return
+	}
+	c.JSON(http.StatusCreated, revision)
+}
+
+func updateCable(c *gin.Context) {
+	var cable Cable
+	if err := c.ShouldBindJSON(&cable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	cable.ID = c.Param("id")
+	if err := validateCable(cable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, updated, err := cableCatalogue.Update(cable)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Кабель не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+func deleteCable(c *gin.Context) {
+	deleted, err := cableCatalogue.Delete(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Кабель не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Версія запису довідника; попередні версії зберігаються для відтворення розрахунків
+type Revision[T any] struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Data      T         `json:"data"`
+}
+
+// Довідник з історією версій кожного запису, що зберігається у JSON-файлі
+type Catalogue[T any] struct {
+	mu    sync.Mutex
+	path  string
+	id    func(T) string
+	items map[string][]Revision[T]
+}
+
+// Довідник із файлу або, якщо файлу ще немає, з початкових даних як перших версій
+func NewCatalogue[T any](path string, seed []T, id func(T) string) (*Catalogue[T], error) {
+	catalogue := &Catalogue[T]{path: path, id: id, items: map[string][]Revision[T]{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		now := time.Now().UTC()
+		for _, item := range seed {
+			catalogue.items[id(item)] = []Revision[T]{{Version: 1, UpdatedAt: now, Data: item}}
+		}
+		return catalogue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &catalogue.items); err != nil {
+		return nil, err
+	}
+	return catalogue, nil
+}
+
+// Останні версії всіх записів
+func (vc *Catalogue[T]) List() []Revision[T] {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	latest := make([]Revision[T], 0, len(vc.items))
+	for _, revisions := range vc.items {
+		latest = append(latest, revisions[len(revisions)-1])
+	}
+	sort.Slice(latest, func(i, j int) bool { return vc.id(latest[i].Data) < vc.id(latest[j].Data) })
+	return latest
+}
+
+// Версія запису; version 0 означає останню
+func (vc *Catalogue[T]) Get(id string, version int) (Revision[T], bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	revisions, ok := vc.items[id]
+	if !ok {
+		return Revision[T]{}, false
+	}
+	if version == 0 {
+		return revisions[len(revisions)-1], true
+	}
+	if version < 0 || version > len(revisions) {
+		return Revision[T]{}, false
+	}
+	return revisions[version-1], true
+}
+
+// Усі версії запису від першої до останньої
+func (vc *Catalogue[T]) History(id string) ([]Revision[T], bool) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	revisions, ok := vc.items[id]
+	return append([]Revision[T](nil), revisions...), ok
+}
+
+// Додавання нового запису як першої версії; false, якщо запис з таким id уже є
+func (vc *Catalogue[T]) Create(item T) (Revision[T], bool, error) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	id := vc.id(item)
+	if _, ok := vc.items[id]; ok {
+		return Revision[T]{}, false, nil
+	}
+	revision := Revision[T]{Version: 1, UpdatedAt: time.Now().UTC(), Data: item}
+	vc.items[id] = []Revision[T]{revision}
+	if err := vc.save(); err != nil {
+		delete(vc.items, id)
+		return Revision[T]{}, true, err
+	}
+	return revision, true, nil
+}
+
+// Збереження наявного запису як нової версії; false, якщо запису з таким id немає
+func (vc *Catalogue[T]) Update(item T) (Revision[T], bool, error) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	id := vc.id(item)
+	revisions, ok := vc.items[id]
+	if !ok {
+		return Revision[T]{}, false, nil
+	}
+	revision := Revision[T]{Version: len(revisions) + 1, UpdatedAt: time.Now().UTC(), Data: item}
+	vc.items[id] = append(revisions, revision)
+	if err := vc.save(); err != nil {
+		vc.items[id] = revisions
+		return Revision[T]{}, true, err
+	}
+	return revision, true, nil
+}
+
+// Видалення запису разом з історією версій
+func (vc *Catalogue[T]) Delete(id string) (bool, error) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+
+	revisions, ok := vc.items[id]
+	if !ok {
+		return false, nil
+	}
+	delete(vc.items, id)
+	if err := vc.save(); err != nil {
+		vc.items[id] = revisions
+		return true, err
+	}
+	return true, nil
+}
+
+func (vc *Catalogue[T]) save() error {
+	data, err := json.MarshalIndent(vc.items, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := vc.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, vc.path)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// Таблиця економічної густини струму за замовчуванням
+const defaultJekTable = "pue"
+
+// Назви типів провідників і матеріалів у таблицях для числових кодів запиту
+var conductorTypeNames = map[ConductorType]string{
+	UNSHIELDED:                "unshielded",
+	PAPER_AND_RUBBER_CABLES:   "paper-rubber-cable",
+	RUBBER_AND_PLASTIC_CABLES: "rubber-plastic-cable",
+}
+
+var conductorMaterialNames = map[ConductorMaterial]string{
+	COPPER:   "copper",
+	ALUMINUM: "aluminum",
+}
+
+// Економічна густина струму, А/мм², для діапазону часу використання максимуму
+// навантаження Tm, год; MaxTemp = 0 означає відсутність верхньої межі
+type JekRange struct {
+	MinTemp  float64 `json:"minTemp"`
+	MaxTemp  float64 `json:"maxTemp,omitempty"`
+	JekValue float64 `json:"jek"`
+}
+
+// Діапазони jek для типу провідника з певного матеріалу
+type JekConductor struct {
+	Type     string     `json:"type"`
+	Material string     `json:"material"`
+	Ranges   []JekRange `json:"ranges"`
+}
+
+// Таблиця економічної густини струму (норми певного документа або регіону)
+type JekTable struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Region     string         `json:"region,omitempty"`
+	Conductors []JekConductor `json:"conductors"`
+}
+
+// Початкова таблиця за ПУЕ
+var seedJekTables = []JekTable{
+	{
+		ID:   defaultJekTable,
+		Name: "ПУЕ, табл. 1.3.36",
+		Conductors: []JekConductor{
+			{Type: "unshielded", Material: "copper", Ranges: []JekRange{{1000, 3000, 2.5}, {3000, 5000, 2.1}, {5000, 0, 1.8}}},
+			{Type: "unshielded", Material: "aluminum", Ranges: []JekRange{{1000, 3000, 1.3}, {3000, 5000, 1.1}, {5000, 0, 1.0}}},
+			{Type: "paper-rubber-cable", Material: "copper", Ranges: []JekRange{{1000, 3000, 3.0}, {3000, 5000, 2.5}, {5000, 0, 2.0}}},
+			{Type: "paper-rubber-cable", Material: "aluminum", Ranges: []JekRange{{1000, 3000, 1.6}, {3000, 5000, 1.4}, {5000, 0, 1.2}}},
+			{Type: "rubber-plastic-cable", Material: "copper", Ranges: []JekRange{{1000, 3000, 3.5}, {3000, 5000, 3.1}, {5000, 0, 2.7}}},
+			{Type: "rubber-plastic-cable", Material: "aluminum", Ranges: []JekRange{{1000, 3000, 1.9}, {3000, 5000, 1.7}, {5000, 0, 1.6}}},
+		},
+	},
+}
+
+var jekCatalogue *Catalogue[JekTable]
+
+func validateJekTable(table JekTable) error {
+	if table.ID == "" || table.Name == "" {
+		return errors.New("Таблиця повинна мати id та name")
+	}
+	if len(table.Conductors) == 0 {
+		return errors.New("Таблиця повинна містити хоча б один тип провідника")
+	}
+
+	seen := map[[2]string]bool{}
+	for _, conductor := range table.Conductors {
+		if conductor.Type == "" || conductor.Material == "" {
+			return errors.New("Для провідника потрібно задати type та material")
+		}
+		key := [2]string{conductor.Type, conductor.Material}
+		if seen[key] {
+			return fmt.Errorf("Провідник %s (%s) задано двічі", conductor.Type, conductor.Material)
+		}
+		seen[key] = true
+
+		if len(conductor.Ranges) == 0 {
+			return fmt.Errorf("Для провідника %s (%s) не задано діапазонів Tm", conductor.Type, conductor.Material)
+		}
+		for i, r := range conductor.Ranges {
+			if r.JekValue <= 0 {
+				return fmt.Errorf("Густина струму для %s (%s) повинна бути додатною", conductor.Type, conductor.Material)
+			}
+			if r.MinTemp < 0 || (r.MaxTemp != 0 && r.MaxTemp <= r.MinTemp) {
+				return fmt.Errorf("Некоректний діапазон Tm %g–%g для %s (%s)", r.MinTemp, r.MaxTemp, conductor.Type, conductor.Material)
+			}
+			if i > 0 {
+				previous := conductor.Ranges[i-1]
+				if previous.MaxTemp == 0 || r.MinTemp < previous.MaxTemp {
+					return fmt.Errorf("Діапазони Tm для %s (%s) повинні йти за зростанням без перекриття", conductor.Type, conductor.Material)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Економічна густина струму для провідника за часом використання максимуму Tm
+func (table JekTable) jek(conductorType, conductorMaterial string, Tm float64) (float64, error) {
+	for _, conductor := range table.Conductors {
+		if conductor.Type != conductorType || conductor.Material != conductorMaterial {
+			continue
+		}
+		for _, r := range conductor.Ranges {
+			if Tm >= r.MinTemp && (r.MaxTemp == 0 || Tm <= r.MaxTemp) {
+				return r.JekValue, nil
+			}
+		}
+	}
+
+	return 0.0, fmt.Errorf("У таблиці %s немає значення jek для провідника %s (%s) при Tm = %g год", table.ID, conductorType, conductorMaterial, Tm)
+}
+
+// Номер версії з параметра запиту version; 0 — остання
+func requestVersion(c *gin.Context) (int, error) {
+	value := c.Query("version")
+	if value == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		return 0, errors.New("Версія повинна бути додатним цілим числом")
+	}
+	return version, nil
+}
+
+func listJekTables(c *gin.Context) {
+	c.JSON(http.StatusOK, jekCatalogue.List())
+}
+
+func getJekTable(c *gin.Context) {
+	version, err := requestVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	table, ok := jekCatalogue.Get(c.Param("id"), version)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Таблицю густини струму не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+func getJekTableHistory(c *gin.Context) {
+	history, ok := jekCatalogue.History(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Таблицю густини струму не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+func createJekTable(c *gin.Context) {
+	var table JekTable
+	if err := c.ShouldBindJSON(&table); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := validateJekTable(table); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, created, err := jekCatalogue.Create(table)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !created {
+		c.JSON(http.StatusConflict, gin.H{"error": "Таблиця з таким id вже існує"})
+		return
+	}
+	c.JSON(http.StatusCreated, revision)
+}
+
+// Оновлення створює нову версію таблиці, попередні лишаються доступними
+func updateJekTable(c *gin.Context) {
+	var table JekTable
+	if err := c.ShouldBindJSON(&table); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	table.ID = c.Param("id")
+	if err := validateJekTable(table); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revision, updated, err := jekCatalogue.Update(table)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Таблицю густини струму не знайдено"})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+func deleteJekTable(c *gin.Context) {
+	deleted, err := jekCatalogue.Delete(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Таблицю густини струму не знайдено"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
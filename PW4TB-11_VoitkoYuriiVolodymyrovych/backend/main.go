@@ -7,6 +7,7 @@ import (
 	"log"
 	"math"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -35,12 +36,17 @@ type CalculationRequest1 struct {
 	Ct                float64 `json:"Ct"`
 	ConductorType     int     `json:"ConductorType"`
 	ConductorMaterial int     `json:"ConductorMaterial"`
-}
 
-type JekRange struct {
-	MinTemp  float64
-	MaxTemp  float64
-	JekValue float64
+	// Таблиця jek і її версія (0 — остання); назви типу провідника та матеріалу
+	// з таблиці замінюють числові коди ConductorType і ConductorMaterial
+	JekTable              string `json:"JekTable,omitempty"`
+	JekVersion            int    `json:"JekVersion,omitempty"`
+	ConductorTypeName     string `json:"ConductorTypeName,omitempty"`
+	ConductorMaterialName string `json:"ConductorMaterialName,omitempty"`
+
+	// Марка кабелю з каталогу і її версія; без неї вибирається перша відповідна марка
+	CableID      string `json:"CableID,omitempty"`
+	CableVersion int    `json:"CableVersion,omitempty"`
 }
 
 type CalculationRequest2 struct {
@@ -64,59 +70,6 @@ type CalculationRequest3 struct {
 	X_0    float64 `json:"X_0"`
 }
 
-func getJek(conductorType ConductorType, conductorMaterial ConductorMaterial, Tm float64) (float64, error) {
-	jekValues := map[ConductorType]map[ConductorMaterial][]JekRange{
-		UNSHIELDED: {
-			COPPER: {
-				{1000.0, 3000.0, 2.5},
-				{3000.0, 5000.0, 2.1},
-				{5000.0, math.MaxFloat64, 1.8},
-			},
-			ALUMINUM: {
-				{1000.0, 3000.0, 1.3},
-				{3000.0, 5000.0, 1.1},
-				{5000.0, math.MaxFloat64, 1.0},
-			},
-		},
-		PAPER_AND_RUBBER_CABLES: {
-			COPPER: {
-				{1000.0, 3000.0, 3.0},
-				{3000.0, 5000.0, 2.5},
-				{5000.0, math.MaxFloat64, 2.0},
-			},
-			ALUMINUM: {
-				{1000.0, 3000.0, 1.6},
-				{3000.0, 5000.0, 1.4},
-				{5000.0, math.MaxFloat64, 1.2},
-			},
-		},
-		RUBBER_AND_PLASTIC_CABLES: {
-			COPPER: {
-				{1000.0, 3000.0, 3.5},
-				{3000.0, 5000.0, 3.1},
-				{5000.0, math.MaxFloat64, 2.7},
-			},
-			ALUMINUM: {
-				{1000.0, 3000.0, 1.9},
-				{3000.0, 5000.0, 1.7},
-				{5000.0, math.MaxFloat64, 1.6},
-			},
-		},
-	}
-
-	if ranges, ok := jekValues[conductorType]; ok {
-		if materialRanges, ok := ranges[conductorMaterial]; ok {
-			for _, r := range materialRanges {
-				if Tm >= r.MinTemp && Tm <= r.MaxTemp {
-					return r.JekValue, nil
-				}
-			}
-		}
-	}
-
-	return 0.0, fmt.Errorf("no jek value found for the given parameters")
-}
-
 func calculate1(c *gin.Context) {
 	var req CalculationRequest1
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -127,9 +80,32 @@ func calculate1(c *gin.Context) {
 	Im := req.Sm / 2.0 / math.Sqrt(3.0) / req.Unom
 	Im_pa := 2 * Im
 
-	jek, err := getJek(ConductorType(req.ConductorType), ConductorMaterial(req.ConductorMaterial), req.Tm)
+	tableID := req.JekTable
+	if tableID == "" {
+		tableID = defaultJekTable
+	}
+	table, ok := jekCatalogue.Get(tableID, req.JekVersion)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Таблицю густини струму не знайдено: " + tableID})
+		return
+	}
+	conductorType, conductorMaterial := req.ConductorTypeName, req.ConductorMaterialName
+	if conductorType == "" {
+		if conductorType, ok = conductorTypeNames[ConductorType(req.ConductorType)]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Невідомий тип провідника: %d", req.ConductorType)})
+			return
+		}
+	}
+	if conductorMaterial == "" {
+		if conductorMaterial, ok = conductorMaterialNames[ConductorMaterial(req.ConductorMaterial)]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Невідомий матеріал провідника: %d", req.ConductorMaterial)})
+			return
+		}
+	}
+
+	jek, err := table.Data.jek(conductorType, conductorMaterial, req.Tm)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -139,11 +115,24 @@ func calculate1(c *gin.Context) {
 	}
 	Smin := req.Ik * 1000 * math.Sqrt(req.Tf) / req.Ct
 
+	cable, err := findCable(req.CableID, req.CableVersion, conductorType, conductorMaterial, req.Unom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	selection := selectSection(cable, Sek, Smin, Im_pa)
+
 	c.JSON(http.StatusOK, gin.H{
 		"Im":    Im,
 		"Im_pa": Im_pa,
 		"Sek":   Sek,
 		"Smin":  Smin,
+		"jek":   jek,
+		"jekTable": gin.H{
+			"id":      table.Data.ID,
+			"version": table.Version,
+		},
+		"selection": selection,
 	})
 }
 
@@ -226,11 +215,30 @@ func calculate3(c *gin.Context) {
 }
 
 func main() {
+	jekTablesPath := os.Getenv("JEK_TABLES_PATH")
+	if jekTablesPath == "" {
+		jekTablesPath = "jek_tables.json"
+	}
+	var err error
+	jekCatalogue, err = NewCatalogue(jekTablesPath, seedJekTables, func(table JekTable) string { return table.ID })
+	if err != nil {
+		log.Fatalf("Jek tables loading error: %v", err)
+	}
+
+	cablesPath := os.Getenv("CABLES_PATH")
+	if cablesPath == "" {
+		cablesPath = "cables.json"
+	}
+	cableCatalogue, err = NewCatalogue(cablesPath, seedCables, func(cable Cable) string { return cable.ID })
+	if err != nil {
+		log.Fatalf("Cable catalogue loading error: %v", err)
+	}
+
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Content-Type"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -240,6 +248,20 @@ func main() {
 	r.POST("/api/calculate2", calculate2)
 	r.POST("/api/calculate3", calculate3)
 
+	r.GET("/api/jek-tables", listJekTables)
+	r.GET("/api/jek-tables/:id", getJekTable)
+	r.GET("/api/jek-tables/:id/versions", getJekTableHistory)
+	r.POST("/api/jek-tables", createJekTable)
+	r.PUT("/api/jek-tables/:id", updateJekTable)
+	r.DELETE("/api/jek-tables/:id", deleteJekTable)
+
+	r.GET("/api/cables", listCables)
+	r.GET("/api/cables/:id", getCable)
+	r.GET("/api/cables/:id/versions", getCableHistory)
+	r.POST("/api/cables", createCable)
+	r.PUT("/api/cables/:id", updateCable)
+	r.DELETE("/api/cables/:id", deleteCable)
+
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("Server startup error: %v", err)
 	}